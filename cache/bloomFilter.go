@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/hashing"
+	"github.com/wsss777/LRUCache/logger"
+	"go.uber.org/zap"
+)
+
+// defaultBloomLoadTimeout 启动时从 KeySource 加载键的超时时间
+const defaultBloomLoadTimeout = time.Minute
+
+// KeySource 枚举数据源中所有存在的键，用于启动时填充布隆过滤器
+type KeySource interface {
+	Keys(ctx context.Context, fn func(key string)) error
+}
+
+// KeySourceFunc 函数类型实现 KeySource 接口
+type KeySourceFunc func(ctx context.Context, fn func(key string)) error
+
+// Keys 实现 KeySource 接口
+func (f KeySourceFunc) Keys(ctx context.Context, fn func(key string)) error {
+	return f(ctx, fn)
+}
+
+// BloomFilter 并发安全的布隆过滤器，只增不删
+type BloomFilter struct {
+	bits   []uint64 // 位数组
+	m      uint64   // 位数
+	k      uint64   // 哈希函数个数
+	fpRate float64  // 期望误判率
+	added  int64    // 已添加的键数量
+}
+
+// NewBloomFilter 根据预计元素数量和期望误判率创建布隆过滤器
+func NewBloomFilter(expectedItems uint64, fpRate float64) *BloomFilter {
+	if expectedItems == 0 {
+		expectedItems = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	// m = -n*ln(p) / (ln2)^2, k = m/n * ln2
+	m := uint64(math.Ceil(-float64(expectedItems) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(expectedItems) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &BloomFilter{
+		bits:   make([]uint64, (m+63)/64),
+		m:      m,
+		k:      k,
+		fpRate: fpRate,
+	}
+}
+
+// Add 添加一个键
+func (b *BloomFilter) Add(key string) {
+	h1, h2 := bloomHash(key)
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		atomic.OrUint64(&b.bits[pos/64], 1<<(pos%64))
+	}
+	atomic.AddInt64(&b.added, 1)
+}
+
+// MayContain 判断键是否可能存在，返回 false 表示一定不存在
+func (b *BloomFilter) MayContain(key string) bool {
+	h1, h2 := bloomHash(key)
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		if atomic.LoadUint64(&b.bits[pos/64])&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// EstimatedFPRate 根据当前置位比例估算实际误判率
+func (b *BloomFilter) EstimatedFPRate() float64 {
+	var set int
+	for i := range b.bits {
+		set += bits.OnesCount64(atomic.LoadUint64(&b.bits[i]))
+	}
+	return math.Pow(float64(set)/float64(b.m), float64(b.k))
+}
+
+// Stats 返回布隆过滤器统计信息
+func (b *BloomFilter) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"bits":         b.m,
+		"hashes":       b.k,
+		"added":        atomic.LoadInt64(&b.added),
+		"fp_rate":      b.fpRate,
+		"estimated_fp": b.EstimatedFPRate(),
+	}
+}
+
+// bloomHash 使用 FNV-1a 计算两个基础哈希值，用于双重哈希
+func bloomHash(key string) (uint64, uint64) {
+	h1 := hashing.FNV1aString(key)
+	h2 := h1>>33 | h1<<31
+	// h2 为奇数，保证步长不为 0
+	return h1, h2 | 1
+}
+
+// WithBloomFilter 启用布隆过滤器：本地未命中时先查询过滤器，一定不存在的键直接返回 ErrNotFound。
+// 只有从 source 完整加载所有键之后过滤器才会拒绝键；source 为 nil 时过滤器不完整，永远不拒绝任何键。
+// 之后通过 Set、其他节点同步的写入和加载得到的键都会加入过滤器
+func WithBloomFilter(expectedItems uint64, fpRate float64, source KeySource) GroupOption {
+	return func(g *Group) {
+		g.bloom = NewBloomFilter(expectedItems, fpRate)
+		g.bloomSource = source
+	}
+}
+
+// startBloomFilter 从 KeySource 填充布隆过滤器，填充完成前不拒绝任何键
+func (g *Group) startBloomFilter() {
+	// 没有键来源时无法得知数据源中有哪些键，过滤器保持未就绪
+	if g.bloom == nil || g.bloomSource == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultBloomLoadTimeout)
+		defer cancel()
+		if err := g.bloomSource.Keys(ctx, g.bloom.Add); err != nil {
+			// 加载失败时过滤器不完整，保持未就绪以免误拒存在的键
+			logger.L().Error("failed to load bloom filter keys",
+				zap.String("group", g.name),
+				zap.Error(err))
+			return
+		}
+		atomic.StoreInt32(&g.bloomReady, 1)
+		logger.L().Info("bloom filter loaded",
+			zap.String("group", g.name),
+			zap.Int64("keys", atomic.LoadInt64(&g.bloom.added)))
+	}()
+}
+
+// bloomRejects 判断布隆过滤器是否确定键不存在。集群模式下其他节点的写入只同步给键的所有者，
+// 只有所有者的过滤器包含启动后写入的键，因此只在本节点是所有者时拒绝
+func (g *Group) bloomRejects(key string) bool {
+	if g.bloom == nil || atomic.LoadInt32(&g.bloomReady) == 0 {
+		return false
+	}
+	if g.peers != nil {
+		if owner := g.peers.PickPeers(key, 1); len(owner) == 0 || !owner[0].Self {
+			return false
+		}
+	}
+	return !g.bloom.MayContain(key)
+}
+
+// bloomAdd 将键加入布隆过滤器
+func (g *Group) bloomAdd(key string) {
+	if g.bloom != nil {
+		g.bloom.Add(key)
+	}
+}
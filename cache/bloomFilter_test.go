@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试布隆过滤器无漏判且误判率接近配置值
+func TestBloomFilter(t *testing.T) {
+	const n = 10000
+	bf := NewBloomFilter(n, 0.01)
+	for i := 0; i < n; i++ {
+		bf.Add("key" + strconv.Itoa(i))
+	}
+
+	for i := 0; i < n; i++ {
+		if !bf.MayContain("key" + strconv.Itoa(i)) {
+			t.Fatalf("已添加的键 key%d 被判定为不存在", i)
+		}
+	}
+
+	var fp int
+	for i := 0; i < n; i++ {
+		if bf.MayContain("missing" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.03 {
+		t.Fatalf("误判率过高: %.4f", rate)
+	}
+}
+
+// 测试没有键来源时过滤器不拒绝任何键，未写入过的键仍从数据源加载
+func TestGroupBloomFilterWithoutSource(t *testing.T) {
+	g := NewGroup("bloom-nil-source", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte("v-" + key), nil
+	}), WithBloomFilter(100, 0.01, nil))
+	defer g.Close()
+
+	view, err := g.Get(context.Background(), "never-set")
+	if err != nil {
+		t.Fatalf("未写入过的键应从数据源加载: %v", err)
+	}
+	if view.String() != "v-never-set" {
+		t.Fatalf("值错误: %q", view.String())
+	}
+	if ready := g.Stats()["bloom_ready"]; ready != false {
+		t.Fatalf("没有键来源时过滤器不应就绪")
+	}
+}
+
+// 测试完整加载键来源后拒绝不存在的键，之后写入和同步过来的键不被拒绝
+func TestGroupBloomFilterWithSource(t *testing.T) {
+	var loads int64
+	source := KeySourceFunc(func(ctx context.Context, fn func(key string)) error {
+		fn("exists")
+		return nil
+	})
+	g := NewGroup("bloom-source", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt64(&loads, 1)
+		return []byte("v-" + key), nil
+	}), WithBloomFilter(100, 0.01, source))
+	defer g.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for g.Stats()["bloom_ready"] != true {
+		if time.Now().After(deadline) {
+			t.Fatal("过滤器未就绪")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := g.Get(context.Background(), "exists"); err != nil {
+		t.Fatalf("存在的键应能加载: %v", err)
+	}
+	if _, err := g.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("不存在的键应被拒绝，得到 %v", err)
+	}
+	if n := atomic.LoadInt64(&loads); n != 1 {
+		t.Fatalf("被拒绝的键不应调用数据源，加载次数 %d", n)
+	}
+
+	// 其他节点同步过来的写入也加入过滤器
+	peerCtx := context.WithValue(context.Background(), "from_peer", true)
+	wire := g.newView([]byte("v")).WireBytes()
+	if err := g.Set(peerCtx, "synced", wire); err != nil {
+		t.Fatal(err)
+	}
+	g.mainCache.Delete("synced")
+	if _, err := g.Get(context.Background(), "synced"); err != nil {
+		t.Fatalf("同步写入的键不应被拒绝: %v", err)
+	}
+}
@@ -1,20 +1,89 @@
 package cache
 
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/logger"
+	"go.uber.org/zap"
+)
+
 // ByteView 只读的字节视图，用于缓存数据
 type ByteView struct {
 	b []byte
+	e time.Time // 逻辑过期时间，零值表示永不过期
+	z bool      // b 是否为带压缩标记头的编码格式
+	t []string  // 标签，用于按标签批量失效
+	n *int64    // 解码失败计数，指向所属组的统计，仅 z 为 true 时设置
 }
 
+// Len 返回值在缓存中占用的字节数，启用压缩时为压缩后的大小
 func (b ByteView) Len() int {
 	return len(b.b)
 }
 
+// ByteSlice 返回解码后数据的拷贝，解码失败时返回 nil，需要区分错误时使用 Decode
 func (b ByteView) ByteSlice() []byte {
-	return cloneBytes(b.b)
+	data, err := b.Decode()
+	if err != nil {
+		return nil
+	}
+	return data
 }
 
+// String 返回解码后的字符串，解码失败时返回空字符串
 func (b ByteView) String() string {
-	return string(b.b)
+	data, _ := b.data()
+	return string(data)
+}
+
+// Decode 返回解码后数据的拷贝，数据损坏或解压失败时返回错误
+func (b ByteView) Decode() ([]byte, error) {
+	data, err := b.data()
+	if err != nil {
+		return nil, err
+	}
+	// decodeValue 未压缩时返回的是原切片，仍需拷贝
+	return cloneBytes(data), nil
+}
+
+// WireBytes 返回在节点间传输的字节，启用压缩时为编码格式
+func (b ByteView) WireBytes() []byte {
+	return cloneBytes(b.b)
+}
+
+// Tags 返回值的标签
+func (b ByteView) Tags() []string {
+	return b.t
+}
+
+// Expire 返回值的过期时间，零值表示永不过期
+func (b ByteView) Expire() time.Time {
+	return b.e
+}
+
+// Stale 判断值是否已过期，仅在启用 WithStaleGrace 时才可能返回已过期的值
+func (b ByteView) Stale() bool {
+	return !b.e.IsZero() && time.Now().After(b.e)
+}
+
+// data 返回解码后的数据，未压缩时不拷贝，调用方不得修改。
+// 解码失败时记录日志并计入所属组的 decode_errors 统计
+func (b ByteView) data() ([]byte, error) {
+	if !b.z {
+		return b.b, nil
+	}
+	data, err := decodeValue(b.b)
+	if err != nil {
+		if b.n != nil {
+			atomic.AddInt64(b.n, 1)
+		}
+		logger.L().Warn("failed to decode cached value",
+			zap.Int("size", len(b.b)),
+			zap.Error(err))
+		return nil, err
+	}
+	return data, nil
 }
 
 func cloneBytes(b []byte) []byte {
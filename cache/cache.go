@@ -20,6 +20,10 @@ type Cache struct {
 	misses      int64        // 缓存未命中次数
 	initialized int32        // 原子变量，标记缓存是否已初始化
 	closed      int32        // 原子变量，标记缓存是否已关闭
+
+	tagMu   sync.Mutex
+	tagKeys map[string]map[string]struct{} // 标签到键的索引
+	keyTags map[string][]string            // 键到标签的反向索引
 }
 
 // CacheOptions 缓存配置选项
@@ -70,7 +74,7 @@ func (c *Cache) ensureInitialized() {
 			CapPerBucket:    c.opts.CapPerBucket,
 			Level2Cap:       c.opts.Level2Cap,
 			CleanupInterval: c.opts.CleanupTime,
-			OnEvicted:       c.opts.OnEvicted,
+			OnEvicted:       c.onEvicted,
 		}
 		//创建存储实例
 		c.store = store.NewStore(c.opts.CacheType, storeOpts)
@@ -92,10 +96,14 @@ func (c *Cache) Add(key string, value ByteView) {
 
 	}
 	c.ensureInitialized()
+	// 先建立标签索引再写入，写入时淘汰该键会通过 onEvicted 清理索引，不会残留
+	c.indexTags(key, value.t)
 	if err := c.store.Set(key, value); err != nil {
+		c.unindexTags(key)
 		logger.L().Warn("failed to add to a cache",
 			zap.String("key", key),
 			zap.Error(err))
+		return
 	}
 }
 
@@ -129,6 +137,34 @@ func (c *Cache) Get(ctx context.Context, key string) (value ByteView, ok bool) {
 	return ByteView{}, false
 }
 
+// peek 获取值但不计入命中统计，用于内部遍历
+func (c *Cache) peek(key string) (ByteView, bool) {
+	if atomic.LoadInt32(&c.closed) == 1 || atomic.LoadInt32(&c.initialized) == 0 {
+		return ByteView{}, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	val, found := c.store.Get(key)
+	if !found {
+		return ByteView{}, false
+	}
+	bv, ok := val.(ByteView)
+	return bv, ok
+}
+
+// Keys 返回缓存中所有未过期的键，底层存储不支持列出键时返回 nil
+func (c *Cache) Keys() []string {
+	if atomic.LoadInt32(&c.closed) == 1 || atomic.LoadInt32(&c.initialized) == 0 {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if lister, ok := c.store.(store.KeyLister); ok {
+		return lister.Keys()
+	}
+	return nil
+}
+
 // AddWithExpiration 向缓存中添加一个带过期时间的 key-value 对
 func (c *Cache) AddWithExpiration(key string, value ByteView, expirationTime time.Time) {
 	if atomic.LoadInt32(&c.closed) == 1 {
@@ -144,10 +180,13 @@ func (c *Cache) AddWithExpiration(key string, value ByteView, expirationTime tim
 			zap.String("key", key))
 		return
 	}
+	c.indexTags(key, value.t)
 	if err := c.store.SetWithExpiration(key, value, expiration); err != nil {
+		c.unindexTags(key)
 		logger.L().Warn("failed to add to a cache with expiration",
 			zap.String("key", key),
 			zap.Error(err))
+		return
 	}
 }
 
@@ -158,6 +197,7 @@ func (c *Cache) Delete(key string) bool {
 	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	c.unindexTags(key)
 	return c.store.Delete(key)
 }
 
@@ -169,10 +209,96 @@ func (c *Cache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.store.Clear()
+	c.tagMu.Lock()
+	c.tagKeys, c.keyTags = nil, nil
+	c.tagMu.Unlock()
 	atomic.StoreInt64(&c.hits, 0)
 	atomic.StoreInt64(&c.misses, 0)
 }
 
+// DeleteTag 删除带有指定标签的所有键，返回被删除的键
+func (c *Cache) DeleteTag(tag string) []string {
+	keys := c.KeysByTag(tag)
+	for _, key := range keys {
+		c.Delete(key)
+	}
+	return keys
+}
+
+// KeysByTag 返回带有指定标签的所有键
+func (c *Cache) KeysByTag(tag string) []string {
+	c.tagMu.Lock()
+	defer c.tagMu.Unlock()
+	keys := make([]string, 0, len(c.tagKeys[tag]))
+	for key := range c.tagKeys[tag] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// indexTags 记录键的标签，覆盖该键原有的标签
+func (c *Cache) indexTags(key string, tags []string) {
+	c.tagMu.Lock()
+	defer c.tagMu.Unlock()
+	c.unindexTagsLocked(key)
+	if len(tags) == 0 {
+		return
+	}
+	if c.tagKeys == nil {
+		c.tagKeys = make(map[string]map[string]struct{})
+		c.keyTags = make(map[string][]string)
+	}
+	for _, tag := range tags {
+		keys, ok := c.tagKeys[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tagKeys[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	c.keyTags[key] = tags
+}
+
+// unindexTags 移除键的标签索引
+func (c *Cache) unindexTags(key string) {
+	c.tagMu.Lock()
+	defer c.tagMu.Unlock()
+	c.unindexTagsLocked(key)
+}
+
+// unindexTagsLocked 移除键的标签索引，调用前必须持有 tagMu
+func (c *Cache) unindexTagsLocked(key string) {
+	tags, ok := c.keyTags[key]
+	if !ok {
+		return
+	}
+	for _, tag := range tags {
+		delete(c.tagKeys[tag], key)
+		if len(c.tagKeys[tag]) == 0 {
+			delete(c.tagKeys, tag)
+		}
+	}
+	delete(c.keyTags, key)
+}
+
+// onEvicted 存储淘汰键时同步清理标签索引，再调用用户的回调。
+// 只清理被淘汰的值自己的索引：lru2 中同一个键的旧值可能在新值写入后才被淘汰
+func (c *Cache) onEvicted(key string, value store.Value) {
+	c.tagMu.Lock()
+	if bv, ok := value.(ByteView); !ok || sameTags(c.keyTags[key], bv.t) {
+		c.unindexTagsLocked(key)
+	}
+	c.tagMu.Unlock()
+	if c.opts.OnEvicted != nil {
+		c.opts.OnEvicted(key, value)
+	}
+}
+
+// sameTags 判断两个标签切片是否为同一次写入的标签，每次写入都使用新的切片
+func sameTags(a, b []string) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
 // Len 返回缓存的当前存储项数量
 func (c *Cache) Len() int {
 	if atomic.LoadInt32(&c.closed) == 1 || atomic.LoadInt32(&c.initialized) == 0 {
@@ -213,6 +339,9 @@ func (c *Cache) Stats() map[string]interface{} {
 	}
 	if atomic.LoadInt32(&c.initialized) == 1 {
 		stats["size"] = c.Len()
+		c.tagMu.Lock()
+		stats["tags"] = len(c.tagKeys)
+		c.tagMu.Unlock()
 
 		totalRequests := stats["hits"].(int64) + stats["misses"].(int64)
 		if totalRequests > 0 {
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/store"
+)
+
+// taggedView 创建带标签的测试值，每次调用使用新的标签切片
+func taggedView(value string, tags ...string) ByteView {
+	return ByteView{b: []byte(value), t: append([]string(nil), tags...)}
+}
+
+func sortedKeysByTag(c *Cache, tag string) string {
+	keys := c.KeysByTag(tag)
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// 测试按标签查询和删除键
+func TestCacheTags(t *testing.T) {
+	c := NewCache(DefaultCacheOptions())
+	defer c.Close()
+
+	c.Add("a", taggedView("1", "user", "vip"))
+	c.Add("b", taggedView("2", "user"))
+	c.AddWithExpiration("c", taggedView("3", "vip"), time.Now().Add(time.Minute))
+	c.Add("d", taggedView("4"))
+
+	if got := sortedKeysByTag(c, "user"); got != "a,b" {
+		t.Errorf("标签 user 的键为 %q，期望 a,b", got)
+	}
+	if got := sortedKeysByTag(c, "vip"); got != "a,c" {
+		t.Errorf("标签 vip 的键为 %q，期望 a,c", got)
+	}
+
+	// 覆盖写入时替换原有的标签
+	c.Add("b", taggedView("2", "vip"))
+	if got := sortedKeysByTag(c, "user"); got != "a" {
+		t.Errorf("覆盖后标签 user 的键为 %q，期望 a", got)
+	}
+
+	deleted := c.DeleteTag("vip")
+	sort.Strings(deleted)
+	if got := strings.Join(deleted, ","); got != "a,b,c" {
+		t.Errorf("DeleteTag 删除了 %q，期望 a,b,c", got)
+	}
+	for _, key := range deleted {
+		if _, ok := c.Get(context.Background(), key); ok {
+			t.Errorf("键 %s 应当已被删除", key)
+		}
+	}
+	if _, ok := c.Get(context.Background(), "d"); !ok {
+		t.Error("没有标签的键不应被删除")
+	}
+	if got := c.KeysByTag("user"); len(got) != 0 {
+		t.Errorf("标签 user 的键为 %v，期望为空", got)
+	}
+	if got := c.Stats()["tags"]; got != 0 {
+		t.Errorf("标签数为 %v，期望 0", got)
+	}
+}
+
+// 测试淘汰的键同步从标签索引中移除，索引中只有仍在缓存中的键
+func TestCacheTagsEviction(t *testing.T) {
+	for _, cacheType := range []store.CacheType{store.LRU, store.LRU2} {
+		opts := DefaultCacheOptions()
+		opts.CacheType = cacheType
+		opts.MaxBytes = 64
+		opts.BucketCount = 1
+		opts.CapPerBucket = 4
+		opts.Level2Cap = 4
+		var evicted int
+		opts.OnEvicted = func(key string, value store.Value) { evicted++ }
+		c := NewCache(opts)
+
+		for i := 0; i < 20; i++ {
+			c.Add(fmt.Sprintf("key-%02d", i), taggedView("value-of-some-size", "all"))
+		}
+		if evicted == 0 {
+			t.Fatalf("%s: 没有发生淘汰", cacheType)
+		}
+		keys := c.KeysByTag("all")
+		for _, key := range keys {
+			if _, ok := c.peek(key); !ok {
+				t.Errorf("%s: 已淘汰的键 %s 残留在标签索引中", cacheType, key)
+			}
+		}
+		if len(keys) != c.Len() {
+			t.Errorf("%s: 标签索引中有 %d 个键，缓存中有 %d 个", cacheType, len(keys), c.Len())
+		}
+		c.Close()
+	}
+}
+
+// 测试同一个键的旧值被淘汰时不会移除新值的标签索引
+func TestCacheTagsEvictStaleValue(t *testing.T) {
+	c := NewCache(DefaultCacheOptions())
+	defer c.Close()
+
+	old := taggedView("old", "t")
+	c.Add("k", old)
+	c.Add("k", taggedView("new", "t"))
+	c.onEvicted("k", old)
+	if got := sortedKeysByTag(c, "t"); got != "k" {
+		t.Errorf("标签 t 的键为 %q，期望 k", got)
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/cluster"
+	"github.com/wsss777/LRUCache/logger"
+	"github.com/wsss777/LRUCache/singleFlight"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithClusterSingleFlight 启用集群级的加载合并：所有者节点不可用时，非所有者节点不再各自调用 Getter，
+// 而是交给哈希环上的后继节点加载。后继节点为每个键持有一个最长 leaseTTL 的加载租约，
+// 租约期间的所有接替请求共享同一次加载，从而保证整个集群同一时刻每个键至多一个加载
+func WithClusterSingleFlight(leaseTTL time.Duration) GroupOption {
+	return func(g *Group) {
+		g.leases = &singleFlight.Group{Timeout: leaseTTL}
+	}
+}
+
+// isFallbackLoad 判断当前加载是否为其他节点转交过来的接替加载
+func isFallbackLoad(ctx context.Context) bool {
+	return cluster.IsFallbackRequest(ctx)
+}
+
+// loadFromFallback 所有者节点不可用时交给后继节点加载，handled 为 false 表示需要退化为本地加载，
+// fromPeer 表示值是否由其他节点加载
+func (g *Group) loadFromFallback(ctx context.Context, key string) (value ByteView, fromPeer bool, err error, handled bool) {
+	picker, ok := g.peers.(cluster.FallbackPicker)
+	if !ok || g.leases == nil {
+		return ByteView{}, false, nil, false
+	}
+	peer, ok, isSelf := picker.PickFallback(key)
+	if !ok {
+		return ByteView{}, false, nil, false
+	}
+	// 自己就是接替节点，持有租约加载
+	if isSelf {
+		value, err := g.loadWithLease(ctx, key)
+		return value, false, err, true
+	}
+	fallbackPeer, ok := peer.(cluster.FallbackPeer)
+	if !ok {
+		return ByteView{}, false, nil, false
+	}
+
+	atomic.AddInt64(&g.stats.fallbackLoads, 1)
+	bytes, err := fallbackPeer.GetFallback(g.name, key)
+	if status.Code(err) == codes.NotFound {
+		// 接替节点已确认数据源中不存在该键，与所有者的回答等价，不再本地加载
+		return ByteView{}, false, fmt.Errorf("failed to get from fallback peer : %w", ErrNotFound), true
+	}
+	if err != nil {
+		// 所有者和接替节点都不可用，只能退化为本地加载
+		atomic.AddInt64(&g.stats.fallbackErrors, 1)
+		logger.L().Warn("fallback peer unavailable, loading locally",
+			zap.String("group", g.name),
+			zap.String("key", key),
+			zap.Error(err))
+		return ByteView{}, false, nil, false
+	}
+	value, err = g.newWireView(bytes)
+	return value, true, err, true
+}
+
+// loadWithLease 作为接替节点持有租约从数据源加载，租约期间的请求共享同一次加载
+func (g *Group) loadWithLease(ctx context.Context, key string) (ByteView, error) {
+	atomic.AddInt64(&g.stats.leaseLoads, 1)
+	viewi, err := g.leases.DoContext(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return g.loadFromGetter(ctx, key)
+	})
+	if err != nil {
+		return ByteView{}, fmt.Errorf("failed to load with lease : %w", err)
+	}
+	return viewi.(ByteView), nil
+}
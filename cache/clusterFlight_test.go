@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/cluster"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fallbackPeer 测试节点，Get 模拟所有者，GetFallback 模拟接替节点
+type fallbackPeer struct {
+	getErr    error  // 所有者返回的错误
+	value     []byte // 接替节点返回的值
+	err       error  // 接替节点返回的错误
+	fallbacks int32  // GetFallback 的调用次数
+}
+
+func (p *fallbackPeer) Get(group, key string) ([]byte, error) { return nil, p.getErr }
+func (p *fallbackPeer) Set(ctx context.Context, group, key string, value []byte, tags ...string) error {
+	return nil
+}
+func (p *fallbackPeer) Delete(group, key string) (bool, error) { return true, nil }
+func (p *fallbackPeer) Close() error                           { return nil }
+func (p *fallbackPeer) GetFallback(group, key string) ([]byte, error) {
+	atomic.AddInt32(&p.fallbacks, 1)
+	return p.value, p.err
+}
+
+// fallbackPicker 所有者不可用，接替节点为 fallback，selfFallback 为 true 时接替节点是自身
+type fallbackPicker struct {
+	owner, fallback *fallbackPeer
+	selfFallback    bool
+}
+
+func (p *fallbackPicker) PickPeer(key string) (cluster.Peer, bool, bool) { return p.owner, true, false }
+func (p *fallbackPicker) PickPeers(key string, n int) []cluster.PickedPeer {
+	return []cluster.PickedPeer{{Addr: "owner", Peer: p.owner}}
+}
+func (p *fallbackPicker) PickFallback(key string) (cluster.Peer, bool, bool) {
+	if p.selfFallback {
+		return nil, true, true
+	}
+	return p.fallback, true, false
+}
+func (p *fallbackPicker) Close() error { return nil }
+
+// newFallbackGroup 创建所有者不可用的组，返回 Getter 的调用次数
+func newFallbackGroup(name string, picker *fallbackPicker, opts ...GroupOption) (*Group, *int32) {
+	var loads int32
+	opts = append([]GroupOption{WithClusterSingleFlight(time.Second)}, opts...)
+	g := NewGroup(name, 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		return []byte("local"), nil
+	}), opts...)
+	picker.owner = &fallbackPeer{getErr: status.Error(codes.Unavailable, "owner down")}
+	g.RegisterPeers(picker)
+	return g, &loads
+}
+
+// 测试所有者不可用时由接替节点加载，本节点不调用 Getter
+func TestFallbackPeerLoads(t *testing.T) {
+	picker := &fallbackPicker{fallback: &fallbackPeer{}}
+	g, loads := newFallbackGroup("fallback-value", picker)
+	defer g.Close()
+	picker.fallback.value = g.newView([]byte("remote")).WireBytes()
+
+	view, err := g.Get(context.Background(), "k")
+	if err != nil || view.String() != "remote" {
+		t.Fatalf("应返回接替节点加载的值，实际为 %q, %v", view.String(), err)
+	}
+	if n := atomic.LoadInt32(loads); n != 0 {
+		t.Errorf("本节点不应调用 Getter，实际调用 %d 次", n)
+	}
+	if n := g.Stats()["fallback_loads"].(int64); n != 1 {
+		t.Errorf("fallback_loads = %d，期望 1", n)
+	}
+}
+
+// 测试接替节点确认键不存在时返回 ErrNotFound 并写入负缓存，不退化为本地加载
+func TestFallbackPeerNotFound(t *testing.T) {
+	picker := &fallbackPicker{fallback: &fallbackPeer{err: status.Error(codes.NotFound, "key not found")}}
+	g, loads := newFallbackGroup("fallback-not-found", picker, WithNegativeCache(time.Minute))
+	defer g.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := g.Get(context.Background(), "k"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("应返回 ErrNotFound，实际为 %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(loads); n != 0 {
+		t.Errorf("本节点不应调用 Getter，实际调用 %d 次", n)
+	}
+	if n := atomic.LoadInt32(&picker.fallback.fallbacks); n != 1 {
+		t.Errorf("第二次 Get 应命中负缓存，实际请求接替节点 %d 次", n)
+	}
+	stats := g.Stats()
+	if stats["fallback_errors"].(int64) != 0 || stats["negative_hits"].(int64) != 1 {
+		t.Errorf("fallback_errors=%v negative_hits=%v，期望 0 和 1", stats["fallback_errors"], stats["negative_hits"])
+	}
+}
+
+// 测试接替节点也不可用时退化为本地加载
+func TestFallbackPeerUnavailable(t *testing.T) {
+	picker := &fallbackPicker{fallback: &fallbackPeer{err: status.Error(codes.Unavailable, "fallback down")}}
+	g, loads := newFallbackGroup("fallback-unavailable", picker)
+	defer g.Close()
+
+	view, err := g.Get(context.Background(), "k")
+	if err != nil || view.String() != "local" {
+		t.Fatalf("应退化为本地加载，实际为 %q, %v", view.String(), err)
+	}
+	if n := atomic.LoadInt32(loads); n != 1 {
+		t.Errorf("Getter 应调用 1 次，实际调用 %d 次", n)
+	}
+	if n := g.Stats()["fallback_errors"].(int64); n != 1 {
+		t.Errorf("fallback_errors = %d，期望 1", n)
+	}
+}
+
+// 测试接替节点持有租约时并发的接替请求只加载一次
+func TestLoadWithLeaseDedup(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	g := NewGroup("fallback-lease", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return []byte("value"), nil
+	}), WithClusterSingleFlight(time.Second))
+	defer g.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view, err := g.loadWithLease(context.Background(), "k")
+			if err != nil || view.String() != "value" {
+				t.Errorf("租约加载结果: %q, %v", view.String(), err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Errorf("Getter 应调用 1 次，实际调用 %d 次", n)
+	}
+	stats := g.Stats()
+	if stats["lease_loads"].(int64) != 10 || stats["lease_deduplicated"].(int64) != 9 {
+		t.Errorf("lease_loads=%v lease_deduplicated=%v，期望 10 和 9", stats["lease_loads"], stats["lease_deduplicated"])
+	}
+}
+
+// 测试自身是接替节点时持有租约直接加载
+func TestFallbackSelfLoadsWithLease(t *testing.T) {
+	picker := &fallbackPicker{selfFallback: true}
+	g, loads := newFallbackGroup("fallback-self", picker)
+	defer g.Close()
+
+	view, err := g.Get(context.Background(), "k")
+	if err != nil || view.String() != "local" {
+		t.Fatalf("应持有租约本地加载，实际为 %q, %v", view.String(), err)
+	}
+	if n := atomic.LoadInt32(loads); n != 1 {
+		t.Errorf("Getter 应调用 1 次，实际调用 %d 次", n)
+	}
+	if n := g.Stats()["lease_loads"].(int64); n != 1 {
+		t.Errorf("lease_loads = %d，期望 1", n)
+	}
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// Compression 压缩算法，同时作为编码后数据的首字节标记
+type Compression byte
+
+const (
+	CompressionNone  Compression = 0 // 未压缩
+	CompressionFlate Compression = 1 // compress/flate
+	CompressionGzip  Compression = 2 // compress/gzip
+)
+
+// errBadEncoding 编码后数据的首字节标记无法识别
+var errBadEncoding = errors.New("unknown value encoding")
+
+// WithCompression 启用值压缩：不小于 minSize 字节的值在写入存储和发送给其他节点前压缩，
+// 存储的数据带有一字节的标记头，因此压缩与未压缩的值可以共存，ByteView 读取时自动解压。
+// 集群中所有节点的同名组必须使用相同的设置
+func WithCompression(algo Compression, minSize int) GroupOption {
+	return func(g *Group) {
+		g.compression = algo
+		g.compressMinSize = minSize
+	}
+}
+
+// newView 根据组的压缩设置由原始字节创建 ByteView
+func (g *Group) newView(value []byte) ByteView {
+	if g.compression == CompressionNone {
+		return ByteView{b: cloneBytes(value)}
+	}
+	algo := g.compression
+	if len(value) < g.compressMinSize {
+		algo = CompressionNone
+	}
+	encoded, err := encodeValue(algo, value)
+	if err != nil || (algo != CompressionNone && len(encoded) >= len(value)+1) {
+		// 压缩失败或没有收益时保存原始数据
+		encoded, _ = encodeValue(CompressionNone, value)
+	} else if algo != CompressionNone {
+		atomic.AddInt64(&g.stats.compressed, 1)
+		atomic.AddInt64(&g.stats.compressSaved, int64(len(value)+1-len(encoded)))
+	}
+	return ByteView{b: encoded, z: true, n: &g.stats.decodeErrors}
+}
+
+// newWireView 由其他节点传来的字节创建 ByteView，启用压缩时数据已经是编码格式
+func (g *Group) newWireView(data []byte) (ByteView, error) {
+	if g.compression == CompressionNone {
+		return ByteView{b: cloneBytes(data)}, nil
+	}
+	if len(data) == 0 || Compression(data[0]) > CompressionGzip {
+		return ByteView{}, errBadEncoding
+	}
+	return ByteView{b: cloneBytes(data), z: true, n: &g.stats.decodeErrors}, nil
+}
+
+// encodeValue 按算法编码数据，结果首字节为算法标记
+func encodeValue(algo Compression, value []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(algo))
+	var w io.WriteCloser
+	switch algo {
+	case CompressionNone:
+		buf.Write(value)
+		return buf.Bytes(), nil
+	case CompressionFlate:
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		w = fw
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	default:
+		return nil, errBadEncoding
+	}
+	if _, err := w.Write(value); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeValue 解码带标记头的数据，未压缩时直接返回原切片
+func decodeValue(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errBadEncoding
+	}
+	var r io.ReadCloser
+	switch Compression(data[0]) {
+	case CompressionNone:
+		return data[1:], nil
+	case CompressionFlate:
+		r = flate.NewReader(bytes.NewReader(data[1:]))
+	case CompressionGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return nil, err
+		}
+		r = gr
+	default:
+		return nil, errBadEncoding
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// 测试每种压缩设置下写入、读取和节点间传输的往返
+func TestCompressionRoundTrip(t *testing.T) {
+	small := []byte("tiny")
+	large := bytes.Repeat([]byte("compressible value "), 64)
+
+	for _, algo := range []Compression{CompressionNone, CompressionFlate, CompressionGzip} {
+		name := fmt.Sprintf("compression-roundtrip-%d", algo)
+		g := NewGroup(name, 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+			return nil, ErrNotFound
+		}), WithCompression(algo, 16))
+
+		for _, value := range [][]byte{small, large} {
+			key := fmt.Sprintf("key-%d", len(value))
+			if err := g.Set(context.Background(), key, value); err != nil {
+				t.Fatalf("%s: Set 失败: %v", name, err)
+			}
+			view, err := g.Get(context.Background(), key)
+			if err != nil {
+				t.Fatalf("%s: Get 失败: %v", name, err)
+			}
+			if !bytes.Equal(view.ByteSlice(), value) || view.String() != string(value) {
+				t.Errorf("%s: 读取的值与写入的不一致", name)
+			}
+			decoded, err := view.Decode()
+			if err != nil || !bytes.Equal(decoded, value) {
+				t.Errorf("%s: Decode = %d 字节, %v", name, len(decoded), err)
+			}
+
+			// 其他节点收到的编码数据能还原出同样的值
+			wire, err := g.newWireView(view.WireBytes())
+			if err != nil {
+				t.Fatalf("%s: newWireView 失败: %v", name, err)
+			}
+			if wire.String() != string(value) {
+				t.Errorf("%s: 传输后的值与写入的不一致", name)
+			}
+		}
+
+		if algo != CompressionNone {
+			view, _ := g.Get(context.Background(), fmt.Sprintf("key-%d", len(large)))
+			if view.Len() >= len(large) {
+				t.Errorf("%s: 压缩后大小 %d，期望小于 %d", name, view.Len(), len(large))
+			}
+			if got := g.Stats()["compressed_values"].(int64); got != 1 {
+				t.Errorf("%s: compressed_values = %d，期望 1", name, got)
+			}
+		}
+		g.Close()
+	}
+}
+
+// 测试损坏的数据解码失败时返回错误并计入统计
+func TestCompressionDecodeError(t *testing.T) {
+	g := NewGroup("compression-decode-error", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}), WithCompression(CompressionGzip, 0))
+	defer g.Close()
+
+	view, err := g.newWireView([]byte{byte(CompressionGzip), 'b', 'a', 'd'})
+	if err != nil {
+		t.Fatalf("newWireView 失败: %v", err)
+	}
+	if _, err := view.Decode(); err == nil {
+		t.Error("损坏的数据应当解码失败")
+	}
+	if view.ByteSlice() != nil || view.String() != "" {
+		t.Error("解码失败时应当返回空值")
+	}
+	if got := g.Stats()["decode_errors"].(int64); got != 3 {
+		t.Errorf("decode_errors = %d，期望 3", got)
+	}
+
+	if _, err := g.newWireView([]byte{0xff}); !errors.Is(err, errBadEncoding) {
+		t.Errorf("未知的编码标记应当返回 errBadEncoding，实际为 %v", err)
+	}
+}
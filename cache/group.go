@@ -8,10 +8,13 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/wsss777/LRUCache/circuitBreaker"
 	"github.com/wsss777/LRUCache/cluster"
 	"github.com/wsss777/LRUCache/logger"
 	"github.com/wsss777/LRUCache/singleFlight"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -28,6 +31,12 @@ var ErrValueRequired = errors.New("value is required")
 // ErrGroupClosed 组已关闭错误
 var ErrGroupClosed = errors.New("cache group is closed")
 
+// ErrTagRequired 标签不能为空错误
+var ErrTagRequired = errors.New("tag is required")
+
+// ErrNotFound 数据源中不存在该键，Getter 应返回（或包装）此错误以便启用负缓存
+var ErrNotFound = errors.New("key not found")
+
 // Getter 加载键值的回调函数接口
 type Getter interface {
 	Get(ctx context.Context, key string) ([]byte, error)
@@ -41,6 +50,11 @@ func (f GetterFunc) Get(ctx context.Context, key string) ([]byte, error) {
 	return f(ctx, key)
 }
 
+// TaggedGetter 可选接口，Getter 实现后加载的值会带上标签，便于按标签批量失效
+type TaggedGetter interface {
+	GetWithTags(ctx context.Context, key string) ([]byte, []string, error)
+}
+
 // Group 是一个缓存命名空间
 type Group struct {
 	name       string
@@ -51,18 +65,68 @@ type Group struct {
 	expiration time.Duration // 缓存过期时间，0表示永不过期
 	closed     int32         // 原子变量，标记组是否已关闭
 	stats      groupStats    // 统计信息
+
+	negCache    *Cache        // 负缓存，记录数据源中不存在的键
+	negativeTTL time.Duration // 负缓存过期时间，0表示不启用
+
+	bloom       *BloomFilter // 布隆过滤器，拦截一定不存在的键
+	bloomSource KeySource    // 启动时填充布隆过滤器的键来源
+	bloomReady  int32        // 原子变量，标记布隆过滤器是否已填充完成
+
+	refreshAhead float64       // 剩余有效期低于该比例时提前异步刷新，0表示不启用
+	staleGrace   time.Duration // 过期后继续保留并返回旧值的宽限期，0表示不启用
+	refreshing   sync.Map      // 正在后台刷新的键
+
+	leases *singleFlight.Group // 作为接替节点时的加载租约，nil表示不启用集群级合并
+
+	loadTimeout time.Duration // 每次调用 Getter 的超时时间，0表示不限制
+	retry       *RetryPolicy  // Getter 失败后的重试策略，nil表示不重试
+	loadSem     chan struct{} // 限制同时调用 Getter 数量的信号量，nil表示不限制
+
+	breaker *circuitBreaker.Breaker // Getter 熔断器，nil表示不启用
+
+	writer *writer // 写入后端存储的策略，nil表示只写缓存
+
+	compression     Compression // 值压缩算法，CompressionNone表示不压缩
+	compressMinSize int         // 启用压缩的最小值大小
+
+	hotCache *Cache          // 热点缓存，存放从其他节点取回的值，nil表示不启用
+	hotOpts  HotCacheOptions // 热点缓存配置
+	hotKeys  *topK           // 热点键统计，nil表示不启用
+
+	migrator *migrator // 节点加入时迁移键，nil表示不启用
+
+	readReplicas int // 读取时考虑的副本数，<=1 表示只访问所有者
 }
 
 // groupStats 保存组的统计信息
 type groupStats struct {
-	loads        int64 // 加载次数
-	localHits    int64 // 本地缓存命中次数
-	localMisses  int64 // 本地缓存未命中次数
-	peerHits     int64 // 从对等节点获取成功次数
-	peerMisses   int64 // 从对等节点获取失败次数
-	loaderHits   int64 // 从加载器获取成功次数
-	loaderErrors int64 // 从加载器获取失败次数
-	loadDuration int64 // 加载总耗时（纳秒）
+	loads            int64 // 加载次数
+	localHits        int64 // 本地缓存命中次数
+	localMisses      int64 // 本地缓存未命中次数
+	peerHits         int64 // 从对等节点获取成功次数
+	peerMisses       int64 // 从对等节点获取失败次数
+	loaderHits       int64 // 从加载器获取成功次数
+	loaderErrors     int64 // 从加载器获取失败次数
+	loadDuration     int64 // 加载总耗时（纳秒）
+	negativeHits     int64 // 负缓存命中次数
+	bloomRejects     int64 // 被布隆过滤器拦截的次数
+	refreshes        int64 // 后台刷新次数
+	staleHits        int64 // 返回过期旧值的次数
+	fallbackLoads    int64 // 转交给接替节点加载的次数
+	fallbackErrors   int64 // 接替节点加载失败的次数
+	leaseLoads       int64 // 作为接替节点持有租约加载的次数
+	loadTimeouts     int64 // 调用 Getter 超时次数
+	loadRetries      int64 // 调用 Getter 重试次数
+	loadWaits        int64 // 因并发限制而排队的加载次数
+	loadRejects      int64 // 排队期间被取消的加载次数
+	compressed       int64 // 压缩存储的值数量
+	compressSaved    int64 // 压缩节省的字节数
+	decodeErrors     int64 // 读取时解码或解压失败的次数
+	hotHits          int64 // 热点缓存命中次数
+	tagInvalidations int64 // 按标签失效的次数
+	imported         int64 // 接收其他节点迁移过来的条目数
+	replicaReads     int64 // 从副本读取成功的次数
 }
 
 // GroupOption 定义Group的配置选项
@@ -109,6 +173,11 @@ func NewGroup(name string, cacheBytes int64, getter Getter, opts ...GroupOption)
 	for _, opt := range opts {
 		opt(g)
 	}
+	if g.negativeTTL > 0 {
+		g.negCache = newNegativeCache()
+	}
+	g.startBloomFilter()
+	g.watchMembership()
 
 	//注册到全局组映射
 	groupsMu.Lock()
@@ -144,20 +213,44 @@ func (g *Group) Get(ctx context.Context, key string) (ByteView, error) {
 	if key == "" {
 		return ByteView{}, ErrKeyRequired
 	}
+	if g.hotKeys != nil {
+		g.hotKeys.observe(key)
+	}
 
 	//从本地缓存获取
 	view, ok := g.mainCache.Get(ctx, key)
+	if !ok {
+		view, ok = g.getFromHotCache(ctx, key)
+	}
 	if ok {
 		atomic.AddInt64(&g.stats.localHits, 1)
+		if view.Stale() {
+			// 宽限期内先返回旧值，同时在后台重新加载
+			atomic.AddInt64(&g.stats.staleHits, 1)
+			g.refreshAsync(key)
+		} else {
+			g.maybeRefresh(key, view)
+		}
 		return view, nil
 	}
+	// 检查负缓存，已知不存在的键直接返回
+	if g.isNegative(ctx, key) {
+		atomic.AddInt64(&g.stats.localHits, 1)
+		atomic.AddInt64(&g.stats.negativeHits, 1)
+		return ByteView{}, ErrNotFound
+	}
 	atomic.AddInt64(&g.stats.localMisses, 1)
+	// 布隆过滤器判定一定不存在的键不再加载
+	if g.bloomRejects(key) {
+		atomic.AddInt64(&g.stats.bloomRejects, 1)
+		return ByteView{}, ErrNotFound
+	}
 	// 尝试从其他节点获取或加载
 	return g.load(ctx, key)
 }
 
-// Set 设置缓存值
-func (g *Group) Set(ctx context.Context, key string, value []byte) error {
+// Set 设置缓存值，可以为值附加标签以便通过 InvalidateTag 批量失效
+func (g *Group) Set(ctx context.Context, key string, value []byte, tags ...string) error {
 	//log.Printf("[DEBUG-EXP] Set key=%q | 当前 expiration = %v | 是否 >0: %v", key, g.expiration, g.expiration > 0)
 	// 检查组是否已关闭
 	if atomic.LoadInt32(&g.closed) == 1 {
@@ -171,17 +264,35 @@ func (g *Group) Set(ctx context.Context, key string, value []byte) error {
 	}
 	// 检查是否是从其他节点同步过来的请求
 	isPeerRequest := ctx.Value("from_peer") != nil
-	//创建缓存视图
-	view := ByteView{b: cloneBytes(value)}
-	// 设置到本地缓存
-	if g.expiration > 0 {
-		g.mainCache.AddWithExpiration(key, view, time.Now().Add(g.expiration))
+	//创建缓存视图，同步过来的数据已经是编码格式
+	var view ByteView
+	if isPeerRequest {
+		v, err := g.newWireView(value)
+		if err != nil {
+			return err
+		}
+		view = v
 	} else {
-		g.mainCache.Add(key, view)
+		view = g.newView(value)
+	}
+	if len(tags) > 0 {
+		view.t = append([]string(nil), tags...)
 	}
+	// 写入后端存储，同步过来的请求已由发起节点写过
+	if !isPeerRequest && g.writer != nil {
+		if err := g.writer.set(ctx, key, cloneBytes(value)); err != nil {
+			return fmt.Errorf("failed to write to backing store : %w", err)
+		}
+	}
+	// 键已存在，清除负缓存和热点缓存记录并加入布隆过滤器
+	g.clearNegative(key)
+	g.removeFromHotCache(key)
+	g.bloomAdd(key)
+	// 设置到本地缓存
+	g.populateCache(key, view)
 	// 如果不是从其他节点同步过来的请求，且启用了分布式模式，同步到其他节点
 	if !isPeerRequest && g.peers != nil {
-		go g.syncToPeers(ctx, "set", key, value)
+		go g.syncToPeers(ctx, "set", key, view.b, view.t)
 	}
 	return nil
 }
@@ -194,34 +305,45 @@ func (g *Group) Delete(ctx context.Context, key string) error {
 	if key == "" {
 		return ErrKeyRequired
 	}
-	// 从本地缓存删除
-	g.mainCache.Delete(key)
 	// 检查是否是从其他节点同步过来的请求
 	isPeerRequest := ctx.Value("from_peer") != nil
+	// 从后端存储删除，同步过来的请求已由发起节点删过
+	if !isPeerRequest && g.writer != nil {
+		if err := g.writer.delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete from backing store : %w", err)
+		}
+	}
+	// 从本地缓存删除
+	g.mainCache.Delete(key)
+	g.clearNegative(key)
+	g.removeFromHotCache(key)
 	// 如果不是从其他节点同步过来的请求，且启用了分布式模式，同步到其他节点
 	if !isPeerRequest && g.peers != nil {
-		go g.syncToPeers(ctx, "delete", key, nil)
+		go g.syncToPeers(ctx, "delete", key, nil, nil)
 	}
 
 	return nil
 }
 
 // syncToPeers 同步操作到其他节点
-func (g *Group) syncToPeers(ctx context.Context, op string, key string, value []byte) {
+func (g *Group) syncToPeers(ctx context.Context, op string, key string, value []byte, tags []string) {
 	if g.peers == nil {
 		return
 	}
-	// 选择对等节点
-	peer, ok, isSelf := g.peers.PickPeer(key)
-	if !ok || isSelf {
-		return
+	// 写入必须发给键在哈希环上的所有者（以及读副本）。PickPeer 在有界负载模式下可能选出溢出节点，只用于读取
+	for _, peer := range g.writeTargets(key) {
+		g.syncToPeer(peer, op, key, value, tags)
 	}
+}
+
+// syncToPeer 同步操作到一个节点
+func (g *Group) syncToPeer(peer cluster.Peer, op string, key string, value []byte, tags []string) {
 	// 创建同步请求上下文
 	syncCtx := context.WithValue(context.Background(), "from_peer", true)
 	var err error
 	switch op {
 	case "set":
-		err = peer.Set(syncCtx, g.name, key, value)
+		err = peer.Set(syncCtx, g.name, key, value, tags...)
 	case "delete":
 		_, err = peer.Delete(g.name, key)
 	}
@@ -232,6 +354,51 @@ func (g *Group) syncToPeers(ctx context.Context, op string, key string, value []
 	}
 }
 
+// InvalidateTag 删除本地所有带有该标签的键，并广播给所有其他节点，返回本地删除的键数
+func (g *Group) InvalidateTag(ctx context.Context, tag string) (int, error) {
+	if atomic.LoadInt32(&g.closed) == 1 {
+		return 0, ErrGroupClosed
+	}
+	if tag == "" {
+		return 0, ErrTagRequired
+	}
+	keys := g.mainCache.DeleteTag(tag)
+	if g.hotCache != nil {
+		keys = append(keys, g.hotCache.DeleteTag(tag)...)
+	}
+	atomic.AddInt64(&g.stats.tagInvalidations, 1)
+	logger.L().Info("Group invalidate tag",
+		zap.String("name", g.name),
+		zap.String("tag", tag),
+		zap.Int("keys", len(keys)))
+
+	// 广播给其他节点，同步过来的请求不再继续广播
+	isPeerRequest := ctx.Value("from_peer") != nil
+	if !isPeerRequest && g.peers != nil {
+		go g.broadcastInvalidateTag(tag)
+	}
+	return len(keys), nil
+}
+
+// broadcastInvalidateTag 将标签失效广播给所有其他节点
+func (g *Group) broadcastInvalidateTag(tag string) {
+	lister, ok := g.peers.(cluster.PeerLister)
+	if !ok {
+		return
+	}
+	for _, peer := range lister.Peers() {
+		invalidator, ok := peer.(cluster.TagInvalidator)
+		if !ok {
+			continue
+		}
+		if _, err := invalidator.InvalidateTag(g.name, tag); err != nil {
+			logger.L().Error("Error in broadcastInvalidateTag",
+				zap.String("tag", tag),
+				zap.Error(err))
+		}
+	}
+}
+
 // Clear 清空缓存
 func (g *Group) Clear() {
 	// 检查组是否已关闭
@@ -239,6 +406,12 @@ func (g *Group) Clear() {
 		return
 	}
 	g.mainCache.Clear()
+	if g.negCache != nil {
+		g.negCache.Clear()
+	}
+	if g.hotCache != nil {
+		g.hotCache.Clear()
+	}
 	logger.L().Info("Group Clear cache",
 		zap.String("name", g.name))
 }
@@ -250,10 +423,26 @@ func (g *Group) Close() error {
 		return nil
 	}
 
+	// 停止正在进行的迁移
+	if g.migrator != nil {
+		g.migrator.close()
+	}
+
+	// 刷新异步写队列
+	if g.writer != nil {
+		g.writer.close()
+	}
+
 	// 关闭本地缓存
 	if g.mainCache != nil {
 		g.mainCache.Close()
 	}
+	if g.negCache != nil {
+		g.negCache.Close()
+	}
+	if g.hotCache != nil {
+		g.hotCache.Close()
+	}
 
 	// 从全局组映射中移除
 	groupsMu.Lock()
@@ -268,8 +457,28 @@ func (g *Group) Close() error {
 func (g *Group) load(ctx context.Context, key string) (value ByteView, err error) {
 	// 使用 singleflight 确保并发请求只加载一次
 	startTime := time.Now()
-	viewi, err := g.loader.Do(key, func() (interface{}, error) {
-		return g.loadData(ctx, key)
+	// 调用者取消只会让自己放弃等待，共享的加载会继续完成并写入缓存
+	viewi, err := g.loader.DoContext(ctx, key, func(ctx context.Context) (interface{}, error) {
+		view, fromPeer, err := g.loadData(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				g.addNegative(key)
+			}
+			return nil, err
+		}
+		g.bloomAdd(key)
+		// 启用热点缓存时，远程值不进入主缓存
+		if fromPeer && g.hotCache != nil {
+			// 足够热的远程键一定复制到本地，否则按概率放入
+			if g.isHotKey(key) {
+				g.populateHotCache(key, view)
+			} else {
+				g.maybePopulateHotCache(key, view)
+			}
+			return view, nil
+		}
+		// 设置到本地缓存
+		return g.populateCache(key, view), nil
 	})
 	// 记录加载时间
 	loadDuration := time.Since(startTime).Nanoseconds()
@@ -280,49 +489,94 @@ func (g *Group) load(ctx context.Context, key string) (value ByteView, err error
 		atomic.AddInt64(&g.stats.loaderErrors, 1)
 		return ByteView{}, err
 	}
-	view := viewi.(ByteView)
-	// 设置到本地缓存
+	return viewi.(ByteView), nil
+}
+
+// populateCache 将值写入本地缓存，启用过期时间时记录逻辑过期时间
+func (g *Group) populateCache(key string, view ByteView) ByteView {
 	if g.expiration > 0 {
-		g.mainCache.AddWithExpiration(key, view, time.Now().Add(g.expiration))
+		view.e = time.Now().Add(g.expiration)
+		// 启用宽限期时，底层存储多保留一段时间以便返回旧值
+		g.mainCache.AddWithExpiration(key, view, view.e.Add(g.staleGrace))
 	} else {
 		g.mainCache.Add(key, view)
 	}
-	return view, nil
+	return view
 }
 
-// loadData 实际加载数据的方法
-func (g *Group) loadData(ctx context.Context, key string) (value ByteView, err error) {
+// loadData 实际加载数据的方法，fromPeer 表示值是否从其他节点取回
+func (g *Group) loadData(ctx context.Context, key string) (value ByteView, fromPeer bool, err error) {
+	// 其他节点转交过来的接替加载，直接持有租约从数据源加载
+	if g.leases != nil && isFallbackLoad(ctx) {
+		value, err := g.loadWithLease(ctx, key)
+		return value, false, err
+	}
 	// 尝试从远程节点获取
 	if g.peers != nil {
+		if value, err, handled := g.loadFromReplicas(ctx, key); handled {
+			return value, err == nil, err
+		}
 		peer, ok, isSelf := g.peers.PickPeer(key)
 		if ok && !isSelf {
 			value, err := g.getFromPeer(ctx, peer, key)
 			if err == nil {
 				atomic.AddInt64(&g.stats.peerHits, 1)
-				return value, nil
+				return value, true, nil
+			}
+			// 对等节点已确认数据源中不存在该键，无需再查数据源
+			if errors.Is(err, ErrNotFound) {
+				return ByteView{}, false, err
 			}
 
 			atomic.AddInt64(&g.stats.peerMisses, 1)
-			logger.L().Error("failed to get data",
-				zap.Error(err))
+			// 熔断打开时快速失败是预期行为，不再记录错误日志
+			if !errors.Is(err, circuitBreaker.ErrOpen) {
+				logger.L().Error("failed to get data",
+					zap.Error(err))
+			}
+
+			// 所有者不可用，交给后继节点加载
+			if value, fromPeer, err, handled := g.loadFromFallback(ctx, key); handled {
+				return value, fromPeer, err
+			}
 		}
 	}
 	// 从数据源加载
-	bytes, err := g.getter.Get(ctx, key)
+	value, err = g.loadFromGetter(ctx, key)
+	return value, false, err
+}
+
+// loadFromGetter 从数据源加载
+func (g *Group) loadFromGetter(ctx context.Context, key string) (ByteView, error) {
+	bytes, tags, err := g.callGetter(ctx, key)
 	if err != nil {
 		return ByteView{}, fmt.Errorf("failed to get from peer : %w", err)
 	}
 	atomic.AddInt64(&g.stats.loaderHits, 1)
-	return ByteView{b: cloneBytes(bytes)}, nil
+	view := g.newView(bytes)
+	view.t = tags
+	return view, nil
 }
 
 // getFromPeer 从其他节点获取数据
 func (g *Group) getFromPeer(ctx context.Context, peer cluster.Peer, key string) (ByteView, error) {
-	bytes, err := peer.Get(g.name, key)
+	var bytes []byte
+	var tags []string
+	var err error
+	if tp, ok := peer.(cluster.TaggedPeer); ok {
+		bytes, tags, err = tp.GetWithTags(g.name, key)
+	} else {
+		bytes, err = peer.Get(g.name, key)
+	}
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ByteView{}, fmt.Errorf("failed to get from peer : %w", ErrNotFound)
+		}
 		return ByteView{}, fmt.Errorf("failed to get from peer : %w", err)
 	}
-	return ByteView{b: bytes}, nil
+	view, err := g.newWireView(bytes)
+	view.t = tags
+	return view, err
 }
 
 // RegisterPeers 注册PeerPicker
@@ -331,6 +585,7 @@ func (g *Group) RegisterPeers(peers cluster.PeerPicker) {
 		panic("RegisterPeers called more than once")
 	}
 	g.peers = peers
+	g.watchMembership()
 	logger.L().Info("Group RegisterPeers",
 		zap.String("peer", g.name))
 }
@@ -338,16 +593,18 @@ func (g *Group) RegisterPeers(peers cluster.PeerPicker) {
 // Stats 返回缓存统计信息
 func (g *Group) Stats() map[string]interface{} {
 	stats := map[string]interface{}{
-		"name":          g.name,
-		"closed":        atomic.LoadInt32(&g.closed) == 1,
-		"expiration":    g.expiration,
-		"loads":         atomic.LoadInt64(&g.stats.loads),
-		"local_hits":    atomic.LoadInt64(&g.stats.localHits),
-		"local_misses":  atomic.LoadInt64(&g.stats.localMisses),
-		"peer_hits":     atomic.LoadInt64(&g.stats.peerHits),
-		"peer_misses":   atomic.LoadInt64(&g.stats.peerMisses),
-		"loader_hits":   atomic.LoadInt64(&g.stats.loaderHits),
-		"loader_errors": atomic.LoadInt64(&g.stats.loaderErrors),
+		"name":              g.name,
+		"closed":            atomic.LoadInt32(&g.closed) == 1,
+		"expiration":        g.expiration,
+		"loads":             atomic.LoadInt64(&g.stats.loads),
+		"local_hits":        atomic.LoadInt64(&g.stats.localHits),
+		"local_misses":      atomic.LoadInt64(&g.stats.localMisses),
+		"peer_hits":         atomic.LoadInt64(&g.stats.peerHits),
+		"peer_misses":       atomic.LoadInt64(&g.stats.peerMisses),
+		"loader_hits":       atomic.LoadInt64(&g.stats.loaderHits),
+		"loader_errors":     atomic.LoadInt64(&g.stats.loaderErrors),
+		"negative_hits":     atomic.LoadInt64(&g.stats.negativeHits),
+		"tag_invalidations": atomic.LoadInt64(&g.stats.tagInvalidations),
 	}
 
 	// 计算各种命中率
@@ -361,6 +618,18 @@ func (g *Group) Stats() map[string]interface{} {
 		stats["avg_load_time_ms"] = float64(atomic.LoadInt64(&g.stats.loadDuration)) / float64(totalLoads) / float64(time.Millisecond)
 	}
 
+	// 节点选择器的统计，包括每个节点的熔断器状态
+	if sp, ok := g.peers.(interface{ Stats() map[string]interface{} }); ok {
+		for k, v := range sp.Stats() {
+			stats["peers_"+k] = v
+		}
+	}
+
+	// 合并加载的统计
+	for k, v := range g.loader.Stats() {
+		stats["loader_"+k] = v
+	}
+
 	// 添加缓存大小
 	if g.mainCache != nil {
 		cacheStats := g.mainCache.Stats()
@@ -368,6 +637,82 @@ func (g *Group) Stats() map[string]interface{} {
 			stats["cache_"+k] = v
 		}
 	}
+	if g.bloom != nil {
+		stats["bloom_ready"] = atomic.LoadInt32(&g.bloomReady) == 1
+		stats["bloom_rejects"] = atomic.LoadInt64(&g.stats.bloomRejects)
+		for k, v := range g.bloom.Stats() {
+			stats["bloom_"+k] = v
+		}
+	}
+	if g.refreshAhead > 0 || g.staleGrace > 0 {
+		stats["refresh_ahead"] = g.refreshAhead
+		stats["stale_grace"] = g.staleGrace
+		stats["refreshes"] = atomic.LoadInt64(&g.stats.refreshes)
+		stats["stale_hits"] = atomic.LoadInt64(&g.stats.staleHits)
+	}
+	if g.loadTimeout > 0 {
+		stats["load_timeout"] = g.loadTimeout
+		stats["load_timeouts"] = atomic.LoadInt64(&g.stats.loadTimeouts)
+	}
+	if g.retry != nil {
+		stats["load_max_attempts"] = g.retry.MaxAttempts
+		stats["load_retries"] = atomic.LoadInt64(&g.stats.loadRetries)
+	}
+	if g.loadSem != nil {
+		stats["load_max_concurrent"] = cap(g.loadSem)
+		stats["load_concurrent"] = len(g.loadSem)
+		stats["load_waits"] = atomic.LoadInt64(&g.stats.loadWaits)
+		stats["load_rejects"] = atomic.LoadInt64(&g.stats.loadRejects)
+	}
+	if g.breaker != nil {
+		for k, v := range g.breaker.Stats() {
+			stats["breaker_"+k] = v
+		}
+	}
+	if g.compression != CompressionNone {
+		stats["compression"] = g.compression
+		stats["compressed_values"] = atomic.LoadInt64(&g.stats.compressed)
+		stats["compression_saved_bytes"] = atomic.LoadInt64(&g.stats.compressSaved)
+		stats["decode_errors"] = atomic.LoadInt64(&g.stats.decodeErrors)
+	}
+	if g.writer != nil {
+		for k, v := range g.writer.stats() {
+			stats["write_"+k] = v
+		}
+	}
+	if g.leases != nil {
+		stats["fallback_loads"] = atomic.LoadInt64(&g.stats.fallbackLoads)
+		stats["fallback_errors"] = atomic.LoadInt64(&g.stats.fallbackErrors)
+		stats["lease_loads"] = atomic.LoadInt64(&g.stats.leaseLoads)
+		for k, v := range g.leases.Stats() {
+			stats["lease_"+k] = v
+		}
+	}
+	if g.hotCache != nil {
+		stats["hot_hits"] = atomic.LoadInt64(&g.stats.hotHits)
+		for k, v := range g.hotCache.Stats() {
+			stats["hot_cache_"+k] = v
+		}
+	}
+	if g.hotKeys != nil {
+		stats["hot_keys"] = g.hotKeys.top(0)
+	}
+	if g.readReplicas > 1 {
+		stats["read_replicas"] = g.readReplicas
+		stats["replica_reads"] = atomic.LoadInt64(&g.stats.replicaReads)
+	}
+	if g.migrator != nil {
+		for k, v := range g.migrator.stats() {
+			stats["migrate_"+k] = v
+		}
+	}
+	if imported := atomic.LoadInt64(&g.stats.imported); imported > 0 {
+		stats["migrate_imported"] = imported
+	}
+	if g.negCache != nil {
+		stats["negative_ttl"] = g.negativeTTL
+		stats["negative_entries"] = g.negCache.Len()
+	}
 
 	return stats
 }
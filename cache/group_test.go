@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/cluster"
+)
+
+// taggedGetter 为每个键返回标签的测试数据源
+type taggedGetter struct {
+	tags  map[string][]string
+	loads int32
+}
+
+func (g *taggedGetter) Get(ctx context.Context, key string) ([]byte, error) {
+	b, _, err := g.GetWithTags(ctx, key)
+	return b, err
+}
+
+func (g *taggedGetter) GetWithTags(ctx context.Context, key string) ([]byte, []string, error) {
+	atomic.AddInt32(&g.loads, 1)
+	return []byte("v-" + key), g.tags[key], nil
+}
+
+// tagPeer 记录收到的标签失效请求的测试节点
+type tagPeer struct {
+	replicaPeer
+	tags chan string
+}
+
+func (p *tagPeer) InvalidateTag(group, tag string) (int64, error) {
+	p.tags <- group + "/" + tag
+	return 0, nil
+}
+
+// tagPicker 所有键归属自身，同时列出所有其他节点
+type tagPicker struct {
+	peers []cluster.Peer
+}
+
+func (p *tagPicker) PickPeer(key string) (cluster.Peer, bool, bool) { return nil, true, true }
+func (p *tagPicker) PickPeers(key string, n int) []cluster.PickedPeer {
+	return []cluster.PickedPeer{{Self: true}}
+}
+func (p *tagPicker) Peers() []cluster.Peer { return p.peers }
+func (p *tagPicker) Close() error          { return nil }
+
+// 测试 TaggedGetter 返回的标签经加载写入索引，按标签失效后重新加载
+func TestGroupLoadIndexesTags(t *testing.T) {
+	getter := &taggedGetter{tags: map[string][]string{"a": {"user"}, "b": {"user", "vip"}, "c": {"vip"}}}
+	g := NewGroup("group-tags-load", 1<<20, getter)
+	defer g.Close()
+
+	for _, key := range []string{"a", "b", "c"} {
+		view, err := g.Get(context.Background(), key)
+		if err != nil {
+			t.Fatalf("加载 %s 失败: %v", key, err)
+		}
+		if got, want := strings.Join(view.Tags(), ","), strings.Join(getter.tags[key], ","); got != want {
+			t.Errorf("%s 的标签为 %q，期望 %q", key, got, want)
+		}
+	}
+	keys := g.mainCache.KeysByTag("user")
+	sort.Strings(keys)
+	if got := strings.Join(keys, ","); got != "a,b" {
+		t.Fatalf("user 标签索引为 %q，期望 a,b", got)
+	}
+
+	deleted, err := g.InvalidateTag(context.Background(), "user")
+	if err != nil || deleted != 2 {
+		t.Fatalf("InvalidateTag 删除 %d 个键, %v，期望 2", deleted, err)
+	}
+	g.Get(context.Background(), "a")
+	g.Get(context.Background(), "c")
+	if n := atomic.LoadInt32(&getter.loads); n != 4 {
+		t.Errorf("失效的键应重新加载，未失效的键应命中缓存，Getter 调用 %d 次，期望 4", n)
+	}
+}
+
+// 测试标签失效广播给所有实现 TagInvalidator 的节点，同步过来的请求不再广播
+func TestGroupInvalidateTagFansOut(t *testing.T) {
+	g := NewGroup("group-tags-fanout", 1<<20, &taggedGetter{})
+	defer g.Close()
+	peers := []*tagPeer{{tags: make(chan string, 1)}, {tags: make(chan string, 1)}}
+	g.RegisterPeers(&tagPicker{peers: []cluster.Peer{peers[0], peers[1]}})
+
+	if _, err := g.InvalidateTag(context.Background(), "user"); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range peers {
+		select {
+		case got := <-p.tags:
+			if got != "group-tags-fanout/user" {
+				t.Errorf("节点 %d 收到 %q", i, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("节点 %d 未收到标签失效请求", i)
+		}
+	}
+
+	peerCtx := context.WithValue(context.Background(), "from_peer", true)
+	if _, err := g.InvalidateTag(peerCtx, "vip"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-peers[0].tags:
+		t.Errorf("同步过来的请求不应再广播，节点收到 %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/store"
+)
+
+// HotCacheOptions 热点缓存配置
+type HotCacheOptions struct {
+	MaxBytes    int64         // 热点缓存最大内存使用量
+	TTL         time.Duration // 热点值的过期时间，应明显短于主缓存以控制不一致窗口
+	Probability float64       // 从其他节点取回的值被放入热点缓存的概率
+}
+
+// DefaultHotCacheOptions 返回默认的热点缓存配置
+func DefaultHotCacheOptions() HotCacheOptions {
+	return HotCacheOptions{
+		MaxBytes:    1 << 20, // 1MB
+		TTL:         10 * time.Second,
+		Probability: 0.1,
+	}
+}
+
+// WithHotCache 启用热点缓存：从其他节点取回的值不再进入主缓存，而是按概率放入一个独立的小缓存，
+// 避免非本节点拥有的键挤占主缓存，同时让被频繁访问的远程键大概率留在本地
+func WithHotCache(opts HotCacheOptions) GroupOption {
+	return func(g *Group) {
+		cacheOpts := DefaultCacheOptions()
+		cacheOpts.CacheType = store.LRU
+		cacheOpts.MaxBytes = opts.MaxBytes
+		g.hotCache = NewCache(cacheOpts)
+		g.hotOpts = opts
+	}
+}
+
+// getFromHotCache 从热点缓存获取值
+func (g *Group) getFromHotCache(ctx context.Context, key string) (ByteView, bool) {
+	if g.hotCache == nil {
+		return ByteView{}, false
+	}
+	view, ok := g.hotCache.Get(ctx, key)
+	if ok {
+		atomic.AddInt64(&g.stats.hotHits, 1)
+	}
+	return view, ok
+}
+
+// maybePopulateHotCache 按概率将远程值放入热点缓存
+func (g *Group) maybePopulateHotCache(key string, view ByteView) {
+	if rand.Float64() >= g.hotOpts.Probability {
+		return
+	}
+	g.populateHotCache(key, view)
+}
+
+// populateHotCache 将远程值放入热点缓存
+func (g *Group) populateHotCache(key string, view ByteView) {
+	if g.hotOpts.TTL > 0 {
+		g.hotCache.AddWithExpiration(key, view, time.Now().Add(g.hotOpts.TTL))
+	} else {
+		g.hotCache.Add(key, view)
+	}
+}
+
+// removeFromHotCache 移除热点缓存中的键
+func (g *Group) removeFromHotCache(key string) {
+	if g.hotCache != nil {
+		g.hotCache.Delete(key)
+	}
+}
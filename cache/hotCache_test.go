@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newHotCacheGroup 创建启用热点缓存的组，返回 Getter 的调用次数
+func newHotCacheGroup(name string, opts HotCacheOptions, extra ...GroupOption) (*Group, *int32) {
+	var loads int32
+	g := NewGroup(name, 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		return []byte("loaded-" + key), nil
+	}), append([]GroupOption{WithHotCache(opts)}, extra...)...)
+	return g, &loads
+}
+
+// 测试热点缓存中的值在 TTL 内直接返回，过期后重新加载
+func TestHotCacheTTL(t *testing.T) {
+	g, loads := newHotCacheGroup("hot-cache-ttl", HotCacheOptions{
+		MaxBytes:    1 << 10,
+		TTL:         50 * time.Millisecond,
+		Probability: 1,
+	})
+	defer g.Close()
+
+	g.populateHotCache("k", g.newView([]byte("remote")))
+	for i := 0; i < 2; i++ {
+		if view, err := g.Get(context.Background(), "k"); err != nil || view.String() != "remote" {
+			t.Fatalf("应命中热点缓存，实际为 %q, %v", view.String(), err)
+		}
+	}
+	if n := atomic.LoadInt32(loads); n != 0 {
+		t.Fatalf("热点缓存命中时不应加载，实际加载 %d 次", n)
+	}
+	if _, ok := g.mainCache.Get(context.Background(), "k"); ok {
+		t.Error("热点缓存中的值不应进入主缓存")
+	}
+	if n := g.Stats()["hot_hits"].(int64); n != 2 {
+		t.Errorf("hot_hits = %d，期望 2", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if view, err := g.Get(context.Background(), "k"); err != nil || view.String() != "loaded-k" {
+		t.Errorf("热点缓存过期后应重新加载，实际为 %q, %v", view.String(), err)
+	}
+}
+
+// 测试按概率放入热点缓存，Set 和 Delete 移除热点缓存中的副本
+func TestHotCachePopulation(t *testing.T) {
+	never, _ := newHotCacheGroup("hot-cache-never", HotCacheOptions{MaxBytes: 1 << 10, TTL: time.Minute, Probability: 0})
+	defer never.Close()
+	never.maybePopulateHotCache("k", never.newView([]byte("remote")))
+	if _, ok := never.hotCache.Get(context.Background(), "k"); ok {
+		t.Error("概率为 0 时不应放入热点缓存")
+	}
+
+	g, _ := newHotCacheGroup("hot-cache-always", HotCacheOptions{MaxBytes: 1 << 10, TTL: time.Minute, Probability: 1})
+	defer g.Close()
+	for _, key := range []string{"set", "deleted"} {
+		g.maybePopulateHotCache(key, g.newView([]byte("remote")))
+		if _, ok := g.hotCache.Get(context.Background(), key); !ok {
+			t.Fatalf("概率为 1 时 %s 应放入热点缓存", key)
+		}
+	}
+
+	if err := g.Set(context.Background(), "set", []byte("local")); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Delete(context.Background(), "deleted"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"set", "deleted"} {
+		if _, ok := g.hotCache.Get(context.Background(), key); ok {
+			t.Errorf("写入或删除后热点缓存中不应有 %s", key)
+		}
+	}
+	if view, err := g.Get(context.Background(), "set"); err != nil || view.String() != "local" {
+		t.Errorf("Set 后应读到本地值，实际为 %q, %v", view.String(), err)
+	}
+}
+
+// 测试访问频率达到 ReplicateRate 的键被判定为需要复制到热点缓存
+func TestHotKeyReplicateRate(t *testing.T) {
+	g, _ := newHotCacheGroup("hot-cache-replicate", HotCacheOptions{MaxBytes: 1 << 10, TTL: time.Minute, Probability: 0},
+		WithHotKeyTracking(HotKeyOptions{TopK: 1, Capacity: 4, SampleRate: 1, Window: time.Minute, ReplicateRate: 3}))
+	defer g.Close()
+
+	for i := 0; i < 2; i++ {
+		g.Get(context.Background(), "k")
+	}
+	if g.isHotKey("k") {
+		t.Fatal("访问 2 次时不应达到复制阈值")
+	}
+	g.Get(context.Background(), "k")
+	if !g.isHotKey("k") {
+		t.Fatal("访问 3 次时应达到复制阈值")
+	}
+	if g.isHotKey("other") {
+		t.Error("未访问的键不应达到复制阈值")
+	}
+}
@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"container/heap"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HotKey 热点键及其访问频率
+type HotKey struct {
+	Key   string  // 键
+	Count int64   // 估算的访问次数（已按采样率换算）
+	Rate  float64 // 估算的每秒访问次数
+}
+
+// HotKeyOptions 热点键统计配置
+type HotKeyOptions struct {
+	TopK          int           // 报告的热点键数量
+	Capacity      int           // Space-Saving 算法维护的计数器数量，越大越精确
+	SampleRate    float64       // Get 请求的采样比例，取值 (0,1]
+	Window        time.Duration // 统计窗口，窗口结束后重新计数
+	ReplicateRate float64       // 远程键每秒访问次数达到该值时一定放入热点缓存，0表示不启用
+}
+
+// DefaultHotKeyOptions 返回默认的热点键统计配置
+func DefaultHotKeyOptions() HotKeyOptions {
+	return HotKeyOptions{
+		TopK:       10,
+		Capacity:   100,
+		SampleRate: 0.1,
+		Window:     time.Minute,
+	}
+}
+
+// WithHotKeyTracking 启用热点键统计，采样 Get 请求并用 Space-Saving 算法估算访问最多的键。
+// 同时启用 WithHotCache 且设置了 ReplicateRate 时，足够热的远程键会直接复制到本地热点缓存
+func WithHotKeyTracking(opts HotKeyOptions) GroupOption {
+	return func(g *Group) {
+		g.hotKeys = newTopK(opts)
+	}
+}
+
+// HotKeys 返回当前访问最多的 n 个键，n<=0 时返回配置的 TopK 个
+func (g *Group) HotKeys(n int) []HotKey {
+	if g.hotKeys == nil {
+		return nil
+	}
+	return g.hotKeys.top(n)
+}
+
+// isHotKey 判断键的访问频率是否达到复制阈值
+func (g *Group) isHotKey(key string) bool {
+	if g.hotKeys == nil || g.hotKeys.opts.ReplicateRate <= 0 {
+		return false
+	}
+	return g.hotKeys.rate(key) >= g.hotKeys.opts.ReplicateRate
+}
+
+// topKEntry Space-Saving 计数器
+type topKEntry struct {
+	key   string
+	count int64 // 采样计数（含误差）
+	index int   // 在堆中的位置
+}
+
+// topKHeap 按计数排序的最小堆
+type topKHeap []*topKEntry
+
+func (h topKHeap) Len() int           { return len(h) }
+func (h topKHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h topKHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *topKHeap) Push(x interface{}) {
+	e := x.(*topKEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *topKHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// topK 基于 Space-Saving 算法的流式热点键统计
+type topK struct {
+	mu      sync.Mutex
+	opts    HotKeyOptions
+	entries map[string]*topKEntry
+	heap    topKHeap
+	start   time.Time // 当前窗口开始时间
+}
+
+// newTopK 创建热点键统计
+func newTopK(opts HotKeyOptions) *topK {
+	if opts.TopK <= 0 {
+		opts.TopK = 10
+	}
+	if opts.Capacity < opts.TopK {
+		opts.Capacity = opts.TopK
+	}
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		opts.SampleRate = 1
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	return &topK{
+		opts:    opts,
+		entries: make(map[string]*topKEntry, opts.Capacity),
+		start:   time.Now(),
+	}
+}
+
+// observe 按采样率记录一次访问
+func (t *topK) observe(key string) {
+	if t.opts.SampleRate < 1 && rand.Float64() >= t.opts.SampleRate {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate(time.Now())
+
+	if e, ok := t.entries[key]; ok {
+		e.count++
+		heap.Fix(&t.heap, e.index)
+		return
+	}
+	if len(t.heap) < t.opts.Capacity {
+		e := &topKEntry{key: key, count: 1}
+		t.entries[key] = e
+		heap.Push(&t.heap, e)
+		return
+	}
+	// 替换计数最小的键，新键继承其计数（Space-Saving 的过估计误差）
+	min := t.heap[0]
+	delete(t.entries, min.key)
+	min.key = key
+	min.count++
+	t.entries[key] = min
+	heap.Fix(&t.heap, 0)
+}
+
+// rotate 窗口结束后重新计数，调用前必须持有锁
+func (t *topK) rotate(now time.Time) {
+	if now.Sub(t.start) < t.opts.Window {
+		return
+	}
+	t.entries = make(map[string]*topKEntry, t.opts.Capacity)
+	t.heap = t.heap[:0]
+	t.start = now
+}
+
+// top 返回访问最多的 n 个键
+func (t *topK) top(n int) []HotKey {
+	if n <= 0 {
+		n = t.opts.TopK
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.rotate(now)
+
+	entries := make([]*topKEntry, len(t.heap))
+	copy(entries, t.heap)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].count > entries[j].count
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	keys := make([]HotKey, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, t.toHotKey(e, now))
+	}
+	return keys
+}
+
+// rate 返回键的估算访问频率
+func (t *topK) rate(key string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		return 0
+	}
+	return t.toHotKey(e, time.Now()).Rate
+}
+
+// toHotKey 按采样率和窗口时长换算访问次数和频率，调用前必须持有锁
+func (t *topK) toHotKey(e *topKEntry, now time.Time) HotKey {
+	count := float64(e.count) / t.opts.SampleRate
+	elapsed := now.Sub(t.start).Seconds()
+	// 窗口刚开始时避免除以极小的时间得到失真的频率
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	return HotKey{
+		Key:   e.key,
+		Count: int64(count),
+		Rate:  count / elapsed,
+	}
+}
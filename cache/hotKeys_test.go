@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 测试 Space-Saving 按访问次数从高到低报告热点键
+func TestTopKOrdering(t *testing.T) {
+	tk := newTopK(HotKeyOptions{TopK: 3, Capacity: 20, SampleRate: 1, Window: time.Minute})
+	counts := map[string]int{"a": 50, "b": 30, "c": 20}
+	for key, n := range counts {
+		for i := 0; i < n; i++ {
+			tk.observe(key)
+		}
+	}
+	// 大量只出现一次的键轮流占用剩余的计数器。访问次数超过 总次数/Capacity 的键一定保留，
+	// 这里为 200/20=10，三个热点键都不会被挤掉
+	for i := 0; i < 100; i++ {
+		tk.observe(fmt.Sprintf("cold-%d", i))
+	}
+
+	top := tk.top(0)
+	if len(top) != 3 {
+		t.Fatalf("应返回 3 个热点键，实际为 %d 个", len(top))
+	}
+	for i, key := range []string{"a", "b", "c"} {
+		if top[i].Key != key {
+			t.Fatalf("第 %d 个热点键为 %s，期望 %s: %+v", i, top[i].Key, key, top)
+		}
+		// 热点键从未被替换，计数是精确的
+		if top[i].Count != int64(counts[key]) {
+			t.Errorf("%s 的计数为 %d，期望 %d", key, top[i].Count, counts[key])
+		}
+	}
+	if got := tk.top(1); len(got) != 1 || got[0].Key != "a" {
+		t.Errorf("top(1) = %+v", got)
+	}
+}
+
+// 测试新键替换计数最小的键并继承其计数
+func TestTopKReplacement(t *testing.T) {
+	tk := newTopK(HotKeyOptions{TopK: 2, Capacity: 2, SampleRate: 1, Window: time.Minute})
+	for i := 0; i < 3; i++ {
+		tk.observe("a")
+	}
+	tk.observe("b")
+	tk.observe("c")
+
+	top := tk.top(0)
+	if len(top) != 2 || top[0].Key != "a" || top[1].Key != "c" {
+		t.Fatalf("热点键为 %+v，期望 a 和 c", top)
+	}
+	// c 继承了 b 的计数，存在过估计
+	if top[1].Count != 2 {
+		t.Errorf("c 的计数为 %d，期望 2", top[1].Count)
+	}
+}
+
+// 测试窗口结束后重新计数
+func TestTopKWindowRotation(t *testing.T) {
+	tk := newTopK(HotKeyOptions{TopK: 2, Capacity: 2, SampleRate: 1, Window: time.Minute})
+	for i := 0; i < 5; i++ {
+		tk.observe("old")
+	}
+	tk.mu.Lock()
+	tk.start = time.Now().Add(-time.Minute)
+	tk.mu.Unlock()
+
+	tk.observe("new")
+	top := tk.top(0)
+	if len(top) != 1 || top[0].Key != "new" || top[0].Count != 1 {
+		t.Fatalf("新窗口的热点键为 %+v，期望只有 new", top)
+	}
+	if rate := tk.rate("old"); rate != 0 {
+		t.Errorf("上个窗口的键频率为 %v，期望 0", rate)
+	}
+}
+
+// 测试组的 HotKeys 按采样率换算访问次数
+func TestGroupHotKeys(t *testing.T) {
+	g := NewGroup("hot-keys", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte(key), nil
+	}), WithHotKeyTracking(HotKeyOptions{TopK: 2, Capacity: 8, SampleRate: 1, Window: time.Minute}))
+	defer g.Close()
+
+	for i := 0; i < 4; i++ {
+		g.Get(context.Background(), "hot")
+	}
+	g.Get(context.Background(), "warm")
+	top := g.HotKeys(0)
+	if len(top) != 2 || top[0].Key != "hot" || top[0].Count != 4 || top[1].Key != "warm" {
+		t.Fatalf("HotKeys = %+v", top)
+	}
+	// 窗口刚开始时按 1 秒计算频率
+	if top[0].Rate != 4 {
+		t.Errorf("hot 的频率为 %v，期望 4", top[0].Rate)
+	}
+}
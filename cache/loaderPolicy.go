@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/circuitBreaker"
+)
+
+// RetryPolicy Getter 失败后的重试策略，采用带抖动的指数退避
+type RetryPolicy struct {
+	MaxAttempts    int              // 最大尝试次数（包含第一次），<=1 表示不重试
+	InitialBackoff time.Duration    // 第一次重试前的等待时间
+	MaxBackoff     time.Duration    // 等待时间上限
+	Multiplier     float64          // 每次重试等待时间的增长倍数
+	Jitter         float64          // 抖动比例，取值 [0,1]，实际等待时间在 backoff*(1±Jitter) 之间
+	Retryable      func(error) bool // 判断错误是否可重试，nil 表示使用 DefaultRetryable
+}
+
+// DefaultRetryPolicy 返回默认的重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Retryable:      DefaultRetryable,
+	}
+}
+
+// DefaultRetryable 默认的可重试判断：键不存在、调用者取消和熔断拒绝不重试，其余错误均重试
+func DefaultRetryable(err error) bool {
+	return !errors.Is(err, ErrNotFound) && !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, circuitBreaker.ErrOpen)
+}
+
+// normalize 修正无效的配置：至少尝试一次，等待时间不递减，未指定可重试判断时使用默认值
+func (p RetryPolicy) normalize() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 1
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 1
+	}
+	if p.Retryable == nil {
+		p.Retryable = DefaultRetryable
+	}
+	return p
+}
+
+// backoff 计算第 attempt 次重试前的等待时间（attempt 从 1 开始）
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// WithLoadTimeout 设置每次调用 Getter 的超时时间
+func WithLoadTimeout(d time.Duration) GroupOption {
+	return func(g *Group) {
+		g.loadTimeout = d
+	}
+}
+
+// WithRetryPolicy 设置 Getter 失败后的重试策略
+func WithRetryPolicy(p RetryPolicy) GroupOption {
+	return func(g *Group) {
+		p = p.normalize()
+		g.retry = &p
+	}
+}
+
+// WithMaxConcurrentLoads 限制同时调用 Getter 的数量，超出的加载排队等待
+func WithMaxConcurrentLoads(n int) GroupOption {
+	return func(g *Group) {
+		if n > 0 {
+			g.loadSem = make(chan struct{}, n)
+		}
+	}
+}
+
+// callGetter 按超时、重试和并发限制策略调用 Getter
+func (g *Group) callGetter(ctx context.Context, key string) ([]byte, []string, error) {
+	// 获取并发许可
+	if g.loadSem != nil {
+		select {
+		case g.loadSem <- struct{}{}:
+		default:
+			atomic.AddInt64(&g.stats.loadWaits, 1)
+			select {
+			case g.loadSem <- struct{}{}:
+			case <-ctx.Done():
+				atomic.AddInt64(&g.stats.loadRejects, 1)
+				return nil, nil, ctx.Err()
+			}
+		}
+		defer func() { <-g.loadSem }()
+	}
+
+	attempts := 1
+	if g.retry != nil && g.retry.MaxAttempts > 1 {
+		attempts = g.retry.MaxAttempts
+	}
+	var bytes []byte
+	var tags []string
+	var err error
+	for attempt := 1; ; attempt++ {
+		bytes, tags, err = g.callGetterOnce(ctx, key)
+		if err == nil || attempt >= attempts || !g.retry.Retryable(err) {
+			return bytes, tags, err
+		}
+		atomic.AddInt64(&g.stats.loadRetries, 1)
+		timer := time.NewTimer(g.retry.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			// 等待重试期间被取消，返回取消原因，同时保留上一次失败的错误
+			timer.Stop()
+			return nil, nil, fmt.Errorf("load canceled during retry backoff : %w (last error : %w)", ctx.Err(), err)
+		}
+	}
+}
+
+// WithGetterBreaker 为 Getter 启用熔断器，连续失败达到阈值后快速失败，不再调用 Getter。
+// ErrNotFound 和调用者取消不计为失败，c 为 nil 时使用 circuitBreaker.DefaultConfig
+func WithGetterBreaker(c *circuitBreaker.Config) GroupOption {
+	return func(g *Group) {
+		if c == nil {
+			c = circuitBreaker.DefaultConfig
+		}
+		cfg := *c
+		isFailure := cfg.IsFailure
+		cfg.IsFailure = func(err error) bool {
+			if errors.Is(err, ErrNotFound) || errors.Is(err, context.Canceled) {
+				return false
+			}
+			return isFailure == nil || isFailure(err)
+		}
+		g.breaker = circuitBreaker.New("getter:"+g.name, circuitBreaker.WithConfig(&cfg))
+	}
+}
+
+// callGetterOnce 在熔断器和单次超时限制下调用 Getter
+func (g *Group) callGetterOnce(ctx context.Context, key string) ([]byte, []string, error) {
+	if g.breaker == nil {
+		return g.callGetterWithTimeout(ctx, key)
+	}
+	done, err := g.breaker.Allow()
+	if err != nil {
+		return nil, nil, err
+	}
+	bytes, tags, err := g.callGetterWithTimeout(ctx, key)
+	done(err)
+	return bytes, tags, err
+}
+
+// callGetterWithTimeout 在单次超时限制下调用 Getter
+func (g *Group) callGetterWithTimeout(ctx context.Context, key string) ([]byte, []string, error) {
+	if g.loadTimeout <= 0 {
+		return g.fetch(ctx, key)
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.loadTimeout)
+	defer cancel()
+	bytes, tags, err := g.fetch(ctx, key)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		atomic.AddInt64(&g.stats.loadTimeouts, 1)
+	}
+	return bytes, tags, err
+}
+
+// fetch 调用 Getter，Getter 实现了 TaggedGetter 时同时返回标签
+func (g *Group) fetch(ctx context.Context, key string) ([]byte, []string, error) {
+	if tg, ok := g.getter.(TaggedGetter); ok {
+		return tg.GetWithTags(ctx, key)
+	}
+	bytes, err := g.getter.Get(ctx, key)
+	return bytes, nil, err
+}
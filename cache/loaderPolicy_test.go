@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/circuitBreaker"
+)
+
+var errTransient = errors.New("transient")
+
+// 测试单次加载超时后取消 Getter 并计入 load_timeouts
+func TestLoadTimeout(t *testing.T) {
+	g := NewGroup("loader-timeout", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), WithLoadTimeout(20*time.Millisecond))
+	defer g.Close()
+
+	if _, err := g.Get(context.Background(), "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("应返回超时错误，实际为 %v", err)
+	}
+	if n := g.Stats()["load_timeouts"].(int64); n != 1 {
+		t.Errorf("load_timeouts = %d，期望 1", n)
+	}
+}
+
+// 测试可重试错误按 MaxAttempts 重试，不可重试错误立即返回
+func TestRetryPolicy(t *testing.T) {
+	errFatal := errors.New("fatal")
+	var calls int32
+	g := NewGroup("loader-retry", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		if key == "fatal" {
+			return nil, errFatal
+		}
+		return nil, errTransient
+	}), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Retryable:      func(err error) bool { return !errors.Is(err, errFatal) },
+	}))
+	defer g.Close()
+
+	if _, err := g.Get(context.Background(), "transient"); !errors.Is(err, errTransient) {
+		t.Fatalf("应返回最后一次的错误，实际为 %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("可重试错误应调用 Getter 3 次，实际调用 %d 次", n)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	if _, err := g.Get(context.Background(), "fatal"); !errors.Is(err, errFatal) {
+		t.Fatalf("应返回不可重试的错误，实际为 %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("不可重试错误应只调用 Getter 1 次，实际调用 %d 次", n)
+	}
+	if n := g.Stats()["load_retries"].(int64); n != 2 {
+		t.Errorf("load_retries = %d，期望 2", n)
+	}
+}
+
+// 测试等待重试期间加载被取消时返回取消原因。Get 的共享加载不继承调用者的取消信号，
+// 因此直接调用 callGetter
+func TestRetryBackoffCanceled(t *testing.T) {
+	g := NewGroup("loader-retry-cancel", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, errTransient
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second}))
+	defer g.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err := g.callGetter(ctx, "k")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("应返回取消原因，实际为 %v", err)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Errorf("应保留上一次 Getter 的错误，实际为 %v", err)
+	}
+}
+
+// 测试并发加载数达到上限时排队等待，等待期间加载被取消计入 load_rejects
+func TestMaxConcurrentLoads(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	g := NewGroup("loader-concurrency", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		if key == "slow" {
+			close(started)
+			<-release
+		}
+		return []byte(key), nil
+	}), WithMaxConcurrentLoads(1))
+	defer g.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.Get(context.Background(), "slow")
+		done <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := g.callGetter(ctx, "queued"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("排队期间超时应返回取消原因，实际为 %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("持有许可的加载失败: %v", err)
+	}
+	if view, err := g.Get(context.Background(), "queued"); err != nil || view.String() != "queued" {
+		t.Errorf("许可释放后应加载成功，实际为 %q, %v", view.String(), err)
+	}
+
+	stats := g.Stats()
+	if stats["load_waits"].(int64) != 1 || stats["load_rejects"].(int64) != 1 {
+		t.Errorf("load_waits=%v load_rejects=%v，期望 1 和 1", stats["load_waits"], stats["load_rejects"])
+	}
+}
+
+// 测试 Getter 熔断打开后快速失败，不再调用 Getter，ErrNotFound 不计为失败
+func TestGetterBreaker(t *testing.T) {
+	var calls int32
+	g := NewGroup("loader-breaker", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		if key == "missing" {
+			return nil, ErrNotFound
+		}
+		return nil, errTransient
+	}), WithGetterBreaker(&circuitBreaker.Config{FailureThreshold: 2, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1}))
+	defer g.Close()
+
+	for i := 0; i < 3; i++ {
+		g.Get(context.Background(), "missing")
+	}
+	if state := g.Stats()["breaker_state"]; state != "closed" {
+		t.Fatalf("ErrNotFound 不应打开熔断器，实际状态 %v", state)
+	}
+	for i := 0; i < 2; i++ {
+		g.Get(context.Background(), fmt.Sprintf("k%d", i))
+	}
+	atomic.StoreInt32(&calls, 0)
+	if _, err := g.Get(context.Background(), "k2"); !errors.Is(err, circuitBreaker.ErrOpen) {
+		t.Fatalf("熔断打开后应快速失败，实际为 %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("熔断打开后不应调用 Getter，实际调用 %d 次", n)
+	}
+}
+
+// 测试未指定熔断配置时使用默认配置
+func TestGetterBreakerNilConfig(t *testing.T) {
+	g := NewGroup("loader-breaker-nil", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte(key), nil
+	}), WithGetterBreaker(nil))
+	defer g.Close()
+
+	if view, err := g.Get(context.Background(), "k"); err != nil || view.String() != "k" {
+		t.Fatalf("加载失败: %q, %v", view.String(), err)
+	}
+}
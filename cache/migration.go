@@ -0,0 +1,368 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/cluster"
+	"github.com/wsss777/LRUCache/logger"
+	pb "github.com/wsss777/LRUCache/pb"
+	"go.uber.org/zap"
+)
+
+// MigrationOptions 键迁移配置
+type MigrationOptions struct {
+	Rate      int         // 每秒最多迁移的条目数，<=0 表示不限速
+	BatchSize int         // 每批条目数，对端确认一批后删除本地对应的条目
+	Retry     RetryPolicy // 迁移流中断后的重试策略，重试从最后确认的位置继续
+}
+
+// DefaultMigrationOptions 返回默认的迁移配置
+func DefaultMigrationOptions() MigrationOptions {
+	return MigrationOptions{
+		Rate:      1000,
+		BatchSize: 100,
+		Retry:     DefaultRetryPolicy(),
+	}
+}
+
+// WithMigration 启用键迁移：有节点加入哈希环时，把本地缓存中归属已移动到该节点的条目
+// 按批次流式发送给它，对端确认后删除本地条目，避免新节点冷启动造成大量未命中。
+// 需要节点选择器实现 cluster.MembershipNotifier
+func WithMigration(opts MigrationOptions) GroupOption {
+	return func(g *Group) {
+		g.migrator = newMigrator(g, opts)
+	}
+}
+
+// ImportEntry 接收其他节点迁移过来的条目，value 为节点间传输的格式。
+// 本地已有该键时保留本地的值，返回是否写入
+func (g *Group) ImportEntry(key string, value []byte, expire time.Time, tags []string) (bool, error) {
+	if atomic.LoadInt32(&g.closed) == 1 {
+		return false, ErrGroupClosed
+	}
+	if key == "" {
+		return false, ErrKeyRequired
+	}
+	if _, ok := g.mainCache.peek(key); ok {
+		return false, nil
+	}
+	if !expire.IsZero() && time.Now().After(expire) {
+		return false, nil
+	}
+	view, err := g.newWireView(value)
+	if err != nil {
+		return false, err
+	}
+	view.t = tags
+	view.e = expire
+	g.bloomAdd(key)
+	if expire.IsZero() {
+		g.mainCache.Add(key, view)
+	} else {
+		g.mainCache.AddWithExpiration(key, view, expire.Add(g.staleGrace))
+	}
+	atomic.AddInt64(&g.stats.imported, 1)
+	return true, nil
+}
+
+// watchMembership 订阅节点加入事件以触发迁移
+func (g *Group) watchMembership() {
+	if g.migrator == nil || g.peers == nil {
+		return
+	}
+	notifier, ok := g.peers.(cluster.MembershipNotifier)
+	if !ok {
+		logger.L().Warn("peer picker does not support membership notification, migration disabled",
+			zap.String("group", g.name))
+		return
+	}
+	notifier.OnPeerJoin(g.migrator.start)
+}
+
+// migrationJob 一次向某个节点的迁移
+type migrationJob struct {
+	cancel context.CancelFunc
+}
+
+// migrator 负责把归属移动到新节点的条目迁移过去
+type migrator struct {
+	group   *Group
+	opts    MigrationOptions
+	limiter *rateLimiter
+
+	mu     sync.Mutex
+	jobs   map[string]*migrationJob // 每个目标节点正在进行的迁移
+	closed bool                     // 已关闭，不再启动新的迁移
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	started  int64 // 启动的迁移次数
+	migrated int64 // 已迁移并删除的条目数
+	failed   int64 // 重试后仍未迁移的条目数
+	resumes  int64 // 迁移流中断后续传的次数
+}
+
+// newMigrator 创建 migrator
+func newMigrator(g *Group, opts MigrationOptions) *migrator {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	opts.Retry = opts.Retry.normalize()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &migrator{
+		group:   g,
+		opts:    opts,
+		limiter: newRateLimiter(opts.Rate),
+		jobs:    make(map[string]*migrationJob),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// start 找出归属移动到 addr 的本地条目并开始迁移，同一节点已有迁移时取消旧的重新开始
+func (m *migrator) start(addr string, peer cluster.Peer) {
+	g := m.group
+	if atomic.LoadInt32(&g.closed) == 1 {
+		return
+	}
+	target, ok := peer.(cluster.Migrator)
+	if !ok {
+		return
+	}
+
+	var keys []string
+	for _, key := range g.mainCache.Keys() {
+		picked := g.peers.PickPeers(key, 1)
+		if len(picked) > 0 && !picked[0].Self && picked[0].Addr == addr {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	// 固定顺序，续传时从确认位置继续
+	sort.Strings(keys)
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	job := &migrationJob{cancel: cancel}
+	m.mu.Lock()
+	// 与 close 在同一把锁下检查，保证 wg.Wait 开始后不再调用 wg.Add
+	if m.closed {
+		m.mu.Unlock()
+		cancel()
+		return
+	}
+	if prev, exists := m.jobs[addr]; exists {
+		prev.cancel()
+	}
+	m.jobs[addr] = job
+	m.wg.Add(1)
+	m.mu.Unlock()
+	atomic.AddInt64(&m.started, 1)
+
+	logger.L().Info("Migration started",
+		zap.String("group", g.name),
+		zap.String("target", addr),
+		zap.Int("keys", len(keys)))
+
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.mu.Lock()
+			if m.jobs[addr] == job {
+				delete(m.jobs, addr)
+			}
+			m.mu.Unlock()
+			cancel()
+		}()
+		m.run(ctx, addr, target, keys)
+	}()
+}
+
+// run 迁移所有键，流中断时按重试策略从最后确认的位置续传
+func (m *migrator) run(ctx context.Context, addr string, target cluster.Migrator, keys []string) {
+	cursor := 0
+	failures := 0
+	for {
+		n, err := m.stream(ctx, target, keys[cursor:])
+		cursor += n
+		if err == nil {
+			logger.L().Info("Migration finished",
+				zap.String("group", m.group.name),
+				zap.String("target", addr),
+				zap.Int("keys", len(keys)))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if n > 0 {
+			failures = 0
+		}
+		failures++
+		if failures >= m.opts.Retry.MaxAttempts || !m.opts.Retry.Retryable(err) {
+			atomic.AddInt64(&m.failed, int64(len(keys)-cursor))
+			logger.L().Error("Migration failed",
+				zap.String("group", m.group.name),
+				zap.String("target", addr),
+				zap.Int("remaining", len(keys)-cursor),
+				zap.Error(err))
+			return
+		}
+		atomic.AddInt64(&m.resumes, 1)
+		timer := time.NewTimer(m.opts.Retry.backoff(failures))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// stream 通过一个迁移流按批次发送条目，每批等待对端确认后删除本地条目，返回已确认的键数
+func (m *migrator) stream(ctx context.Context, target cluster.Migrator, keys []string) (int, error) {
+	g := m.group
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := target.Migrate(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for start := 0; start < len(keys); start += m.opts.BatchSize {
+		end := start + m.opts.BatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := m.limiter.wait(ctx, end-start); err != nil {
+			return start, err
+		}
+
+		// 已被删除或过期的键直接跳过
+		var entries []*pb.MigrateEntry
+		for i, key := range keys[start:end] {
+			view, ok := g.mainCache.peek(key)
+			if !ok {
+				continue
+			}
+			entry := &pb.MigrateEntry{
+				Group: g.name,
+				Key:   key,
+				Value: view.WireBytes(),
+				Tags:  view.t,
+				Seq:   uint64(start + i + 1),
+			}
+			if !view.e.IsZero() {
+				entry.ExpireUnixNano = view.e.UnixNano()
+			}
+			entries = append(entries, entry)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		last := entries[len(entries)-1]
+		last.Flush = true
+		for _, entry := range entries {
+			if err := stream.Send(entry); err != nil {
+				return start, err
+			}
+		}
+		ack, err := stream.Recv()
+		if err != nil {
+			return start, err
+		}
+		if ack.GetSeq() != last.Seq {
+			return start, errors.New("migration acknowledged an unexpected sequence")
+		}
+
+		// 对端已接收，本节点不再负责这些键
+		for _, entry := range entries {
+			g.mainCache.Delete(entry.Key)
+		}
+		atomic.AddInt64(&m.migrated, int64(len(entries)))
+	}
+
+	// 全部确认后关闭流，等待对端结束
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != nil && err != io.EOF {
+		logger.L().Warn("Migration stream closed with error",
+			zap.String("group", g.name),
+			zap.Error(err))
+	}
+	return len(keys), nil
+}
+
+// close 取消所有迁移并等待结束
+func (m *migrator) close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cancel()
+	m.wg.Wait()
+}
+
+// stats 返回迁移统计信息
+func (m *migrator) stats() map[string]interface{} {
+	m.mu.Lock()
+	running := len(m.jobs)
+	m.mu.Unlock()
+	return map[string]interface{}{
+		"running":  running,
+		"started":  atomic.LoadInt64(&m.started),
+		"migrated": atomic.LoadInt64(&m.migrated),
+		"failed":   atomic.LoadInt64(&m.failed),
+		"resumes":  atomic.LoadInt64(&m.resumes),
+	}
+}
+
+// rateLimiter 按固定速率均匀放行条目
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // 每个条目的间隔，0 表示不限速
+	next     time.Time     // 下一个条目最早的放行时间
+}
+
+// newRateLimiter 创建每秒放行 rate 个条目的限速器
+func newRateLimiter(rate int) *rateLimiter {
+	l := &rateLimiter{}
+	if rate > 0 {
+		l.interval = time.Second / time.Duration(rate)
+	}
+	return l
+}
+
+// wait 等待直到可以放行 n 个条目
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if l.interval == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(time.Duration(n) * l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
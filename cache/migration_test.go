@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/wsss777/LRUCache/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// migrateStream 测试用的迁移流，确认 acks 批后返回 Unavailable，acks<0 表示不中断
+type migrateStream struct {
+	grpc.ClientStream
+	target  *migrateTarget
+	acks    int
+	pending []*pb.MigrateEntry
+	closed  bool
+}
+
+func (s *migrateStream) Send(entry *pb.MigrateEntry) error {
+	s.pending = append(s.pending, entry)
+	return nil
+}
+
+func (s *migrateStream) Recv() (*pb.MigrateAck, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+	if s.acks == 0 {
+		return nil, status.Error(codes.Unavailable, "stream reset")
+	}
+	s.acks--
+	last := s.pending[len(s.pending)-1]
+	s.target.ack(s.pending)
+	s.pending = nil
+	return &pb.MigrateAck{Seq: last.Seq}, nil
+}
+
+func (s *migrateStream) CloseSend() error {
+	s.closed = true
+	return nil
+}
+
+// migrateTarget 测试用的迁移目标，记录每个流中对端确认的键
+type migrateTarget struct {
+	mu      sync.Mutex
+	acks    []int      // 每个流中断前确认的批数
+	streams [][]string // 每个流确认的键
+}
+
+func (t *migrateTarget) Migrate(ctx context.Context) (pb.WsCache_MigrateClient, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	acks := -1
+	if n := len(t.streams); n < len(t.acks) {
+		acks = t.acks[n]
+	}
+	t.streams = append(t.streams, nil)
+	return &migrateStream{target: t, acks: acks}, nil
+}
+
+func (t *migrateTarget) ack(entries []*pb.MigrateEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := len(t.streams) - 1
+	for _, entry := range entries {
+		t.streams[last] = append(t.streams[last], entry.Key)
+	}
+}
+
+// 测试迁移流中断后从最后确认的位置续传，已确认的条目不重复发送
+func TestMigrationResumesFromLastAck(t *testing.T) {
+	g := NewGroup("migration-resume", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}), WithMigration(MigrationOptions{
+		BatchSize: 3,
+		Retry:     RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}))
+	defer g.Close()
+
+	var keys []string
+	for i := 1; i <= 10; i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		keys = append(keys, key)
+		g.populateCache(key, g.newView([]byte("v")))
+	}
+
+	// 第一个流确认 1 批后中断，第二个流确认 2 批后中断，第三个流完成
+	target := &migrateTarget{acks: []int{1, 2}}
+	g.migrator.run(context.Background(), "target", target, keys)
+
+	want := [][]string{keys[0:3], keys[3:9], keys[9:10]}
+	if len(target.streams) != len(want) {
+		t.Fatalf("打开了 %d 个流，期望 %d 个: %v", len(target.streams), len(want), target.streams)
+	}
+	for i := range want {
+		if fmt.Sprint(target.streams[i]) != fmt.Sprint(want[i]) {
+			t.Errorf("第 %d 个流确认的键为 %v，期望 %v", i+1, target.streams[i], want[i])
+		}
+	}
+	for _, key := range keys {
+		if _, ok := g.mainCache.peek(key); ok {
+			t.Errorf("已迁移的键 %s 应从本地删除", key)
+		}
+	}
+	stats := g.migrator.stats()
+	if stats["migrated"].(int64) != 10 || stats["resumes"].(int64) != 2 || stats["failed"].(int64) != 0 {
+		t.Errorf("迁移统计: %v", stats)
+	}
+}
+
+// 测试连续失败达到重试上限后放弃，未确认的条目保留在本地
+func TestMigrationGivesUp(t *testing.T) {
+	g := NewGroup("migration-give-up", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}), WithMigration(MigrationOptions{
+		BatchSize: 2,
+		Retry:     RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	}))
+	defer g.Close()
+
+	keys := []string{"a", "b", "c", "d"}
+	for _, key := range keys {
+		g.populateCache(key, g.newView([]byte("v")))
+	}
+
+	target := &migrateTarget{acks: []int{1, 0, 0}}
+	g.migrator.run(context.Background(), "target", target, keys)
+
+	for i, key := range keys {
+		_, ok := g.mainCache.peek(key)
+		if migrated := i < 2; ok == migrated {
+			t.Errorf("键 %s 在本地: %v", key, ok)
+		}
+	}
+	stats := g.migrator.stats()
+	if stats["migrated"].(int64) != 2 || stats["failed"].(int64) != 2 {
+		t.Errorf("迁移统计: %v", stats)
+	}
+}
+
+// migratePeer 同时实现 Peer 和 Migrator 的测试节点
+type migratePeer struct {
+	*replicaPeer
+	*migrateTarget
+}
+
+// 测试 migrator 关闭后不再启动新的迁移
+func TestMigrationNotStartedAfterClose(t *testing.T) {
+	g := NewGroup("migration-closed", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}), WithMigration(MigrationOptions{}))
+	defer g.Close()
+	var calls []string
+	var mu sync.Mutex
+	owner := &replicaPeer{addr: "owner", calls: &calls, mu: &mu}
+	g.RegisterPeers(&replicaPicker{owner: owner, local: owner})
+	g.populateCache("k", g.newView([]byte("v")))
+
+	g.migrator.close()
+	target := &migrateTarget{}
+	g.migrator.start("owner", &migratePeer{replicaPeer: owner, migrateTarget: target})
+	if n := g.migrator.stats()["started"].(int64); n != 0 {
+		t.Fatalf("关闭后不应启动迁移，实际启动 %d 次", n)
+	}
+	if _, ok := g.mainCache.peek("k"); !ok {
+		t.Error("未迁移的键应保留在本地")
+	}
+}
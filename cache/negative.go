@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/wsss777/LRUCache/store"
+)
+
+// defaultNegativeCacheBytes 负缓存的默认内存上限，只存键，因此无需太大
+const defaultNegativeCacheBytes = 1 << 20 // 1MB
+
+// WithNegativeCache 启用负缓存：Getter 返回 ErrNotFound 时，在 ttl 时间内
+// 对同一个键的 Get 直接返回 ErrNotFound，避免缓存穿透。
+// Set 和 Delete（包括其他节点同步过来的请求）会清除本节点的记录，但写入只同步给
+// 键的所有者和副本，其他节点从所有者得到的 NotFound 最多会在 ttl 时间内继续生效
+func WithNegativeCache(ttl time.Duration) GroupOption {
+	return func(g *Group) {
+		g.negativeTTL = ttl
+	}
+}
+
+// newNegativeCache 创建独立于主缓存的负缓存，避免与正常值争抢容量
+func newNegativeCache() *Cache {
+	opts := DefaultCacheOptions()
+	opts.CacheType = store.LRU
+	opts.MaxBytes = defaultNegativeCacheBytes
+	return NewCache(opts)
+}
+
+// isNegative 判断键是否处于负缓存中
+func (g *Group) isNegative(ctx context.Context, key string) bool {
+	if g.negCache == nil {
+		return false
+	}
+	_, ok := g.negCache.Get(ctx, key)
+	return ok
+}
+
+// addNegative 记录一个不存在的键
+func (g *Group) addNegative(key string) {
+	if g.negCache == nil {
+		return
+	}
+	g.negCache.AddWithExpiration(key, ByteView{}, time.Now().Add(g.negativeTTL))
+}
+
+// clearNegative 移除键的负缓存记录
+func (g *Group) clearNegative(key string) {
+	if g.negCache == nil {
+		return
+	}
+	g.negCache.Delete(key)
+}
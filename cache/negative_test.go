@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newNegativeGroup 创建 Getter 总是返回 ErrNotFound 的组，返回 Getter 的调用次数
+func newNegativeGroup(name string, ttl time.Duration) (*Group, *int32) {
+	var loads int32
+	g := NewGroup(name, 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		return nil, ErrNotFound
+	}), WithNegativeCache(ttl))
+	return g, &loads
+}
+
+// 测试不存在的键写入负缓存，后续 Get 直接返回 ErrNotFound 且不再调用 Getter
+func TestNegativeCacheHit(t *testing.T) {
+	g, loads := newNegativeGroup("negative-hit", time.Minute)
+	defer g.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("应返回 ErrNotFound，实际为 %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(loads); n != 1 {
+		t.Errorf("Getter 应调用 1 次，实际调用 %d 次", n)
+	}
+	stats := g.Stats()
+	if stats["negative_hits"].(int64) != 2 || stats["negative_entries"].(int) != 1 {
+		t.Errorf("negative_hits=%v negative_entries=%v，期望 2 和 1", stats["negative_hits"], stats["negative_entries"])
+	}
+}
+
+// 测试负缓存过期后重新调用 Getter
+func TestNegativeCacheTTL(t *testing.T) {
+	g, loads := newNegativeGroup("negative-ttl", 50*time.Millisecond)
+	defer g.Close()
+
+	g.Get(context.Background(), "missing")
+	time.Sleep(100 * time.Millisecond)
+	if _, err := g.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("应返回 ErrNotFound，实际为 %v", err)
+	}
+	if n := atomic.LoadInt32(loads); n != 2 {
+		t.Errorf("负缓存过期后应重新调用 Getter，实际调用 %d 次", n)
+	}
+	if n := g.Stats()["negative_hits"].(int64); n != 0 {
+		t.Errorf("negative_hits = %d，期望 0", n)
+	}
+}
+
+// 测试 Set 清除负缓存，包括其他节点同步过来的 Set
+func TestNegativeCacheClearedBySet(t *testing.T) {
+	g, _ := newNegativeGroup("negative-set", time.Minute)
+	defer g.Close()
+
+	g.Get(context.Background(), "local")
+	if err := g.Set(context.Background(), "local", []byte("value")); err != nil {
+		t.Fatalf("Set 失败: %v", err)
+	}
+	if view, err := g.Get(context.Background(), "local"); err != nil || view.String() != "value" {
+		t.Errorf("Set 后应读到新值，实际为 %q, %v", view.String(), err)
+	}
+
+	g.Get(context.Background(), "synced")
+	peerCtx := context.WithValue(context.Background(), "from_peer", true)
+	if err := g.Set(peerCtx, "synced", g.newView([]byte("value")).WireBytes()); err != nil {
+		t.Fatalf("同步 Set 失败: %v", err)
+	}
+	if view, err := g.Get(context.Background(), "synced"); err != nil || view.String() != "value" {
+		t.Errorf("同步 Set 后应读到新值，实际为 %q, %v", view.String(), err)
+	}
+	if n := g.Stats()["negative_entries"].(int); n != 0 {
+		t.Errorf("negative_entries = %d，期望 0", n)
+	}
+}
+
+// 测试 Delete 清除负缓存，下次 Get 重新调用 Getter
+func TestNegativeCacheClearedByDelete(t *testing.T) {
+	g, loads := newNegativeGroup("negative-delete", time.Minute)
+	defer g.Close()
+
+	g.Get(context.Background(), "missing")
+	if err := g.Delete(context.Background(), "missing"); err != nil {
+		t.Fatalf("Delete 失败: %v", err)
+	}
+	g.Get(context.Background(), "missing")
+	if n := atomic.LoadInt32(loads); n != 2 {
+		t.Errorf("Delete 后应重新调用 Getter，实际调用 %d 次", n)
+	}
+}
+
+// 测试所有者返回 codes.NotFound 时映射为 ErrNotFound 并写入负缓存
+func TestNegativeCachePeerNotFound(t *testing.T) {
+	var calls []string
+	var mu sync.Mutex
+	g := NewGroup("negative-peer", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, errors.New("数据源不应被调用")
+	}), WithNegativeCache(time.Minute))
+	defer g.Close()
+	owner := &replicaPeer{addr: "owner", err: status.Error(codes.NotFound, "key not found"), calls: &calls, mu: &mu}
+	g.RegisterPeers(&replicaPicker{owner: owner, local: owner})
+
+	for i := 0; i < 2; i++ {
+		if _, err := g.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("应返回 ErrNotFound，实际为 %v", err)
+		}
+	}
+	if len(calls) != 1 {
+		t.Errorf("第二次 Get 应命中负缓存，实际访问所有者 %d 次", len(calls))
+	}
+}
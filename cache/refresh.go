@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/logger"
+	"go.uber.org/zap"
+)
+
+// WithRefreshAhead 启用提前刷新：命中的键剩余有效期不足 fraction*expiration 时，
+// 在后台通过 singleflight 重新加载并原地替换，使热点键不会过期变冷。需配合 WithExpiration 使用
+func WithRefreshAhead(fraction float64) GroupOption {
+	return func(g *Group) {
+		if fraction > 0 && fraction < 1 {
+			g.refreshAhead = fraction
+		}
+	}
+}
+
+// WithStaleGrace 启用 stale-while-revalidate 与 stale-if-error：值过期后仍保留 grace 时间，
+// 期间 Get 立即返回旧值（ByteView.Stale 为 true）并在后台重新加载一次；
+// 若 Getter 失败则继续返回旧值直到宽限期结束。需配合 WithExpiration 使用
+func WithStaleGrace(grace time.Duration) GroupOption {
+	return func(g *Group) {
+		g.staleGrace = grace
+	}
+}
+
+// maybeRefresh 判断命中的值是否临近过期，是则触发后台刷新
+func (g *Group) maybeRefresh(key string, view ByteView) {
+	if g.refreshAhead <= 0 || g.expiration <= 0 || view.e.IsZero() {
+		return
+	}
+	threshold := time.Duration(float64(g.expiration) * g.refreshAhead)
+	if time.Until(view.e) > threshold {
+		return
+	}
+	g.refreshAsync(key)
+}
+
+// refreshAsync 在后台重新加载键，加载失败时保留现有的值
+func (g *Group) refreshAsync(key string) {
+	// 同一个键同时只有一个后台刷新
+	if _, loading := g.refreshing.LoadOrStore(key, struct{}{}); loading {
+		return
+	}
+	atomic.AddInt64(&g.stats.refreshes, 1)
+	go func() {
+		defer g.refreshing.Delete(key)
+		// 刷新与触发它的请求无关，使用独立的上下文
+		_, err := g.load(context.Background(), key)
+		if errors.Is(err, ErrNotFound) {
+			// 数据源确认键已不存在，旧值不再有效，热点缓存中的副本也一并移除
+			g.mainCache.Delete(key)
+			g.removeFromHotCache(key)
+			return
+		}
+		if err != nil {
+			logger.L().Warn("failed to refresh key in background",
+				zap.String("group", g.name),
+				zap.String("key", key),
+				zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitRefreshed 等待键的后台刷新结束
+func waitRefreshed(t *testing.T, g *Group, key string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := g.refreshing.Load(key); !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("键 %s 的后台刷新没有结束", key)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// 测试临近过期的键在后台刷新，并发命中只触发一次刷新
+func TestRefreshAhead(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	g := NewGroup("refresh-ahead", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return []byte("v1"), nil
+		}
+		<-release
+		return []byte("v2"), nil
+	}), WithExpiration(200*time.Millisecond), WithRefreshAhead(0.5))
+	defer g.Close()
+
+	if view, err := g.Get(context.Background(), "key"); err != nil || view.String() != "v1" {
+		t.Fatalf("首次加载结果: %q, %v", view.String(), err)
+	}
+	// 剩余有效期超过阈值时不刷新
+	g.Get(context.Background(), "key")
+	if _, ok := g.refreshing.Load("key"); ok {
+		t.Fatal("剩余有效期充足时不应刷新")
+	}
+	time.Sleep(120 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view, err := g.Get(context.Background(), "key")
+			if err != nil || view.String() != "v1" || view.Stale() {
+				t.Errorf("刷新期间应返回未过期的旧值，实际为 %q, stale=%v, %v", view.String(), view.Stale(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(release)
+	waitRefreshed(t, g, "key")
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("Getter 应调用 2 次，实际为 %d 次", n)
+	}
+	if n := g.Stats()["refreshes"].(int64); n != 1 {
+		t.Errorf("refreshes=%d，期望 1", n)
+	}
+	if view, err := g.Get(context.Background(), "key"); err != nil || view.String() != "v2" {
+		t.Errorf("刷新后的结果: %q, %v", view.String(), err)
+	}
+}
+
+// 测试宽限期内返回旧值，并发请求只触发一次后台刷新
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	g := NewGroup("refresh-stale", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return []byte("v1"), nil
+		}
+		<-release
+		return []byte("v2"), nil
+	}), WithExpiration(50*time.Millisecond), WithStaleGrace(time.Second))
+	defer g.Close()
+
+	if view, err := g.Get(context.Background(), "key"); err != nil || view.String() != "v1" || view.Stale() {
+		t.Fatalf("首次加载结果: %q, stale=%v, %v", view.String(), view.Stale(), err)
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view, err := g.Get(context.Background(), "key")
+			if err != nil || view.String() != "v1" || !view.Stale() {
+				t.Errorf("宽限期内应立即返回旧值，实际为 %q, stale=%v, %v", view.String(), view.Stale(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(release)
+	waitRefreshed(t, g, "key")
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("Getter 应调用 2 次，实际为 %d 次", n)
+	}
+	stats := g.Stats()
+	if stats["refreshes"].(int64) != 1 || stats["stale_hits"].(int64) != 10 {
+		t.Errorf("refreshes=%v stale_hits=%v，期望 1 和 10", stats["refreshes"], stats["stale_hits"])
+	}
+	if view, err := g.Get(context.Background(), "key"); err != nil || view.String() != "v2" || view.Stale() {
+		t.Errorf("刷新后的结果: %q, stale=%v, %v", view.String(), view.Stale(), err)
+	}
+}
+
+// 测试刷新失败时继续返回旧值
+func TestStaleIfError(t *testing.T) {
+	var calls int32
+	g := NewGroup("refresh-stale-error", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return []byte("v1"), nil
+		}
+		return nil, errors.New("source unavailable")
+	}), WithExpiration(50*time.Millisecond), WithStaleGrace(time.Second))
+	defer g.Close()
+
+	g.Get(context.Background(), "key")
+	time.Sleep(60 * time.Millisecond)
+	if view, err := g.Get(context.Background(), "key"); err != nil || view.String() != "v1" {
+		t.Fatalf("刷新失败前应返回旧值，实际为 %q, %v", view.String(), err)
+	}
+	waitRefreshed(t, g, "key")
+	if view, err := g.Get(context.Background(), "key"); err != nil || view.String() != "v1" {
+		t.Fatalf("刷新失败后宽限期内仍应返回旧值，实际为 %q, %v", view.String(), err)
+	}
+}
+
+// 测试刷新时数据源返回 ErrNotFound，主缓存和热点缓存中的旧值都被移除
+func TestRefreshNotFoundRemovesKey(t *testing.T) {
+	g := NewGroup("refresh-not-found", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}), WithExpiration(time.Minute), WithHotCache(DefaultHotCacheOptions()))
+	defer g.Close()
+
+	g.populateCache("main", g.newView([]byte("v")))
+	g.populateHotCache("hot", g.newView([]byte("v")))
+
+	for _, key := range []string{"main", "hot"} {
+		g.refreshAsync(key)
+		waitRefreshed(t, g, key)
+	}
+	if _, ok := g.mainCache.Get(context.Background(), "main"); ok {
+		t.Error("主缓存中的键应被移除")
+	}
+	if _, ok := g.hotCache.Get(context.Background(), "hot"); ok {
+		t.Error("热点缓存中的键应被移除")
+	}
+}
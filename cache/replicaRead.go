@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/wsss777/LRUCache/cluster"
+	"github.com/wsss777/LRUCache/logger"
+	"go.uber.org/zap"
+)
+
+// WithReadReplicas 本地未命中时从键的 n 个副本中读取，按节点选择器给出的顺序依次尝试，
+// 如 ClientPicker 会把本地可用区的副本排在前面。非所有者的副本未命中时从所有者取回并缓存，
+// 成为本可用区的读缓存；写入和删除会同步给所有副本。需要节点选择器实现 cluster.ReadPicker，
+// n<=1 时只访问所有者
+func WithReadReplicas(n int) GroupOption {
+	return func(g *Group) {
+		g.readReplicas = n
+	}
+}
+
+// loadFromReplicas 按读取顺序从副本获取值，handled 为 false 表示未启用副本读取或本节点就是副本，
+// 应按原来的方式访问所有者
+func (g *Group) loadFromReplicas(ctx context.Context, key string) (value ByteView, err error, handled bool) {
+	// 其他节点发来的读取直接访问所有者，避免副本之间互相转发
+	if g.readReplicas <= 1 || ctx.Value("from_peer") != nil {
+		return ByteView{}, nil, false
+	}
+	picker, ok := g.peers.(cluster.ReadPicker)
+	if !ok {
+		return ByteView{}, nil, false
+	}
+	replicas := picker.PickReadPeers(key, g.readReplicas)
+	for _, replica := range replicas {
+		// 本节点是副本或所有者时由本节点负责加载
+		if replica.Self {
+			return ByteView{}, nil, false
+		}
+	}
+
+	for _, replica := range replicas {
+		value, err := g.getFromPeer(ctx, replica.Peer, key)
+		if err == nil {
+			atomic.AddInt64(&g.stats.peerHits, 1)
+			atomic.AddInt64(&g.stats.replicaReads, 1)
+			return value, nil, true
+		}
+		// 副本未命中时已向所有者确认过
+		if errors.Is(err, ErrNotFound) {
+			return ByteView{}, err, true
+		}
+		atomic.AddInt64(&g.stats.peerMisses, 1)
+		logger.L().Warn("failed to read from replica",
+			zap.String("group", g.name),
+			zap.String("replica", replica.Addr),
+			zap.Error(err))
+	}
+	// 所有副本都不可用，按原来的方式处理
+	return ByteView{}, nil, false
+}
+
+// writeTargets 返回写入和删除需要同步的其他节点：启用副本读取时为所有副本，否则为键的所有者
+func (g *Group) writeTargets(key string) []cluster.Peer {
+	n := 1
+	if g.readReplicas > 1 {
+		n = g.readReplicas
+	}
+	var peers []cluster.Peer
+	for _, picked := range g.peers.PickPeers(key, n) {
+		if !picked.Self {
+			peers = append(peers, picked.Peer)
+		}
+	}
+	return peers
+}
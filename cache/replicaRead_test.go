@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/wsss777/LRUCache/cluster"
+)
+
+// replicaPeer 记录被访问顺序的测试节点
+type replicaPeer struct {
+	addr  string
+	value []byte
+	err   error
+	calls *[]string
+	mu    *sync.Mutex
+}
+
+func (p *replicaPeer) Get(group, key string) ([]byte, error) {
+	p.mu.Lock()
+	*p.calls = append(*p.calls, p.addr)
+	p.mu.Unlock()
+	return p.value, p.err
+}
+func (p *replicaPeer) Set(ctx context.Context, group, key string, value []byte, tags ...string) error {
+	return nil
+}
+func (p *replicaPeer) Delete(group, key string) (bool, error) { return true, nil }
+func (p *replicaPeer) Close() error                           { return nil }
+
+// replicaPicker 所有者在远端可用区，另一个副本在本地可用区
+type replicaPicker struct {
+	owner, local *replicaPeer
+}
+
+func (p *replicaPicker) PickPeer(key string) (cluster.Peer, bool, bool) { return p.owner, true, false }
+func (p *replicaPicker) PickPeers(key string, n int) []cluster.PickedPeer {
+	return []cluster.PickedPeer{{Addr: p.owner.addr, Peer: p.owner}, {Addr: p.local.addr, Peer: p.local}}[:n]
+}
+func (p *replicaPicker) PickReadPeers(key string, n int) []cluster.PickedPeer {
+	return []cluster.PickedPeer{{Addr: p.local.addr, Peer: p.local}, {Addr: p.owner.addr, Peer: p.owner}}[:n]
+}
+func (p *replicaPicker) Close() error { return nil }
+
+func newReplicaGroup(t *testing.T, name string, localErr error) (*Group, *[]string) {
+	var calls []string
+	var mu sync.Mutex
+	g := NewGroup(name, 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, errors.New("数据源不应被调用")
+	}), WithReadReplicas(2))
+	wire := g.newView([]byte("value")).WireBytes()
+	g.RegisterPeers(&replicaPicker{
+		owner: &replicaPeer{addr: "owner", value: wire, calls: &calls, mu: &mu},
+		local: &replicaPeer{addr: "local", value: wire, err: localErr, calls: &calls, mu: &mu},
+	})
+	return g, &calls
+}
+
+// 测试读取时先访问本地可用区的副本
+func TestReadPrefersLocalReplica(t *testing.T) {
+	g, calls := newReplicaGroup(t, "replica-read-local", nil)
+	defer g.Close()
+
+	view, err := g.Get(context.Background(), "k")
+	if err != nil || view.String() != "value" {
+		t.Fatalf("读取失败: %v %q", err, view.String())
+	}
+	if len(*calls) != 1 || (*calls)[0] != "local" {
+		t.Fatalf("应只访问本地副本，实际访问 %v", *calls)
+	}
+	if n := g.Stats()["replica_reads"]; n != int64(1) {
+		t.Fatalf("replica_reads = %v", n)
+	}
+}
+
+// 测试本地副本不可用时按顺序访问下一个副本
+func TestReadFallsBackToNextReplica(t *testing.T) {
+	g, calls := newReplicaGroup(t, "replica-read-next", errors.New("unavailable"))
+	defer g.Close()
+
+	if _, err := g.Get(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"local", "owner"}; len(*calls) != 2 || (*calls)[0] != want[0] || (*calls)[1] != want[1] {
+		t.Fatalf("访问顺序 %v，期望 %v", *calls, want)
+	}
+}
+
+// 测试其他节点发来的读取直接访问所有者
+func TestPeerReadGoesToOwner(t *testing.T) {
+	g, calls := newReplicaGroup(t, "replica-read-peer", nil)
+	defer g.Close()
+
+	ctx := context.WithValue(context.Background(), "from_peer", true)
+	if _, err := g.Get(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 1 || (*calls)[0] != "owner" {
+		t.Fatalf("应只访问所有者，实际访问 %v", *calls)
+	}
+}
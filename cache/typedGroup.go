@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec 负责类型 T 与缓存字节之间的编解码
+type Codec[T any] interface {
+	Marshal(v T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSONCodec 使用 encoding/json 编解码
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec[T]) Unmarshal(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// GobCodec 使用 encoding/gob 编解码
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Marshal(v T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec[T]) Unmarshal(data []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
+
+// ProtoCodec 使用 protobuf 编解码，T 为生成的消息指针类型，如 *pb.Request
+type ProtoCodec[T proto.Message] struct{}
+
+func (ProtoCodec[T]) Marshal(v T) ([]byte, error) {
+	return proto.Marshal(v)
+}
+
+func (ProtoCodec[T]) Unmarshal(data []byte) (T, error) {
+	var zero T
+	// 生成的消息类型允许在 nil 指针上获取消息描述，借此创建新实例
+	v := zero.ProtoReflect().Type().New().Interface().(T)
+	err := proto.Unmarshal(data, v)
+	return v, err
+}
+
+// TypedGetter 加载类型 T 的回调函数
+type TypedGetter[T any] func(ctx context.Context, key string) (T, error)
+
+// TypedGroup 带类型的缓存组，Get/Set 直接使用 T，底层存储与节点间传输仍为字节
+type TypedGroup[T any] struct {
+	group *Group
+	codec Codec[T]
+}
+
+// NewTypedGroup 创建带类型的缓存组，getter 加载到的值通过 codec 编码后存入缓存
+func NewTypedGroup[T any](name string, cacheBytes int64, getter TypedGetter[T], codec Codec[T], opts ...GroupOption) *TypedGroup[T] {
+	if getter == nil {
+		panic("nil getter")
+	}
+	g := NewGroup(name, cacheBytes, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		v, err := getter(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return codec.Marshal(v)
+	}), opts...)
+	return &TypedGroup[T]{group: g, codec: codec}
+}
+
+// Typed 将已有的缓存组包装为带类型的缓存组
+func Typed[T any](g *Group, codec Codec[T]) *TypedGroup[T] {
+	return &TypedGroup[T]{group: g, codec: codec}
+}
+
+// Group 返回底层的缓存组
+func (t *TypedGroup[T]) Group() *Group {
+	return t.group
+}
+
+// Get 获取并解码缓存值
+func (t *TypedGroup[T]) Get(ctx context.Context, key string) (T, error) {
+	view, err := t.group.Get(ctx, key)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	data, err := view.data()
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to decode value : %w", err)
+	}
+	v, err := t.codec.Unmarshal(data)
+	if err != nil {
+		return v, fmt.Errorf("failed to decode value : %w", err)
+	}
+	return v, nil
+}
+
+// Set 编码并设置缓存值，可以附加标签
+func (t *TypedGroup[T]) Set(ctx context.Context, key string, value T, tags ...string) error {
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value : %w", err)
+	}
+	return t.group.Set(ctx, key, data, tags...)
+}
+
+// Delete 删除缓存值
+func (t *TypedGroup[T]) Delete(ctx context.Context, key string) error {
+	return t.group.Delete(ctx, key)
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/wsss777/LRUCache/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+type testUser struct {
+	Name string
+	Age  int
+	Tags []string
+}
+
+// testCodecRoundTrip 验证编码后再解码得到相同的值
+func testCodecRoundTrip[T any](t *testing.T, codec Codec[T], v T, equal func(a, b T) bool) {
+	t.Helper()
+	data, err := codec.Marshal(v)
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	got, err := codec.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("解码失败: %v", err)
+	}
+	if !equal(got, v) {
+		t.Fatalf("解码结果 %v 与原值 %v 不一致", got, v)
+	}
+}
+
+// 测试各编解码器的往返
+func TestCodecRoundTrip(t *testing.T) {
+	user := testUser{Name: "alice", Age: 30, Tags: []string{"vip"}}
+	equalUser := func(a, b testUser) bool { return reflect.DeepEqual(a, b) }
+	testCodecRoundTrip[testUser](t, JSONCodec[testUser]{}, user, equalUser)
+	testCodecRoundTrip[testUser](t, GobCodec[testUser]{}, user, equalUser)
+
+	msg := &pb.Request{Group: "g", Key: "k", Value: []byte("v")}
+	testCodecRoundTrip[*pb.Request](t, ProtoCodec[*pb.Request]{}, msg, func(a, b *pb.Request) bool {
+		return proto.Equal(a, b)
+	})
+
+	if _, err := (JSONCodec[testUser]{}).Unmarshal([]byte("not json")); err == nil {
+		t.Error("无效的数据应当解码失败")
+	}
+}
+
+// 测试带类型的缓存组经过加载和设置后得到相同的值
+func TestTypedGroup(t *testing.T) {
+	var loads int
+	tg := NewTypedGroup("typed-group", 1<<20, func(ctx context.Context, key string) (testUser, error) {
+		loads++
+		return testUser{Name: key, Age: 1}, nil
+	}, JSONCodec[testUser]{})
+	defer tg.Group().Close()
+
+	for i := 0; i < 2; i++ {
+		got, err := tg.Get(context.Background(), "bob")
+		if err != nil || !reflect.DeepEqual(got, testUser{Name: "bob", Age: 1}) {
+			t.Fatalf("Get 结果: %+v, %v", got, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Getter 应调用 1 次，实际为 %d 次", loads)
+	}
+
+	want := testUser{Name: "carol", Age: 40, Tags: []string{"admin"}}
+	if err := tg.Set(context.Background(), "carol", want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := tg.Get(context.Background(), "carol"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Set 后 Get 结果: %+v, %v", got, err)
+	}
+
+	// 底层组中的字节无法按类型解码时返回错误
+	if err := tg.Group().Set(context.Background(), "broken", []byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tg.Get(context.Background(), "broken"); err == nil {
+		t.Error("无法解码的值应当返回错误")
+	}
+}
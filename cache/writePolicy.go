@@ -0,0 +1,361 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/logger"
+	"go.uber.org/zap"
+)
+
+// ErrWriteQueueFull 异步写队列已满
+var ErrWriteQueueFull = errors.New("write-behind queue is full")
+
+// ErrWriteQueueClosed 异步写队列已关闭，不再接受写入
+var ErrWriteQueueClosed = errors.New("write-behind queue is closed")
+
+// defaultWriteFlushTimeout 关闭组时等待异步写队列刷新的最长时间
+const defaultWriteFlushTimeout = 10 * time.Second
+
+// Setter 将键值写入后端存储的接口
+type Setter interface {
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// SetterFunc 函数类型实现 Setter 接口
+type SetterFunc func(ctx context.Context, key string, value []byte) error
+
+// Set 实现 Setter 接口
+func (f SetterFunc) Set(ctx context.Context, key string, value []byte) error {
+	return f(ctx, key, value)
+}
+
+// Deleter 从后端存储删除键的接口
+type Deleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// DeleterFunc 函数类型实现 Deleter 接口
+type DeleterFunc func(ctx context.Context, key string) error
+
+// Delete 实现 Deleter 接口
+func (f DeleterFunc) Delete(ctx context.Context, key string) error {
+	return f(ctx, key)
+}
+
+// BatchSetter 可选接口，支持批量写入的后端存储在异步写模式下会一次写入整批数据
+type BatchSetter interface {
+	SetBatch(ctx context.Context, values map[string][]byte) error
+}
+
+// WriteBehindOptions 异步写配置
+type WriteBehindOptions struct {
+	BatchSize     int           // 每批最多写入的键数
+	FlushInterval time.Duration // 定期刷新间隔
+	QueueSize     int           // 待写入键数上限，超出时 Set/Delete 返回 ErrWriteQueueFull
+	Retry         RetryPolicy   // 写入失败后的重试策略
+}
+
+// DefaultWriteBehindOptions 返回默认的异步写配置
+func DefaultWriteBehindOptions() WriteBehindOptions {
+	return WriteBehindOptions{
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		QueueSize:     10000,
+		Retry:         DefaultRetryPolicy(),
+	}
+}
+
+// WithWriteThrough 启用同步写：Set/Delete 先写后端存储，成功后再更新缓存。
+// setter 为 nil 时使用同时实现了 Setter 的 Getter，deleter 同理
+func WithWriteThrough(setter Setter, deleter Deleter) GroupOption {
+	return func(g *Group) {
+		g.writer = newWriter(g, setter, deleter, nil)
+	}
+}
+
+// WithWriteBehind 启用异步写：Set/Delete 先更新缓存，再由后台队列按批次写入后端存储，
+// 同一个键的多次写入会合并为最后一次，关闭组时刷新队列
+func WithWriteBehind(setter Setter, deleter Deleter, opts WriteBehindOptions) GroupOption {
+	return func(g *Group) {
+		g.writer = newWriter(g, setter, deleter, &opts)
+	}
+}
+
+// writeOp 一次待写入的操作，value 为 nil 表示删除
+type writeOp struct {
+	key   string
+	value []byte
+}
+
+// writer 负责把缓存写操作同步到后端存储
+type writer struct {
+	group   *Group
+	setter  Setter
+	deleter Deleter
+	opts    *WriteBehindOptions // nil 表示同步写
+
+	mu        sync.Mutex
+	pending   map[string]writeOp // 按键合并的待写入操作
+	order     []string           // 键的入队顺序
+	notify    chan struct{}
+	closeCh   chan struct{} // 在持有 mu 时关闭，之后入队的操作返回 ErrWriteQueueClosed
+	doneCh    chan struct{}
+	closeOnce sync.Once
+
+	errors  int64 // 同步写失败次数
+	flushed int64 // 异步写成功的键数
+	failed  int64 // 异步写重试后仍失败的键数
+	retries int64 // 异步写重试次数
+	batches int64 // 异步写批次数
+}
+
+// newWriter 创建 writer，异步写模式下启动后台刷新协程
+func newWriter(g *Group, setter Setter, deleter Deleter, opts *WriteBehindOptions) *writer {
+	if setter == nil {
+		setter, _ = g.getter.(Setter)
+	}
+	if deleter == nil {
+		deleter, _ = g.getter.(Deleter)
+	}
+	w := &writer{
+		group:   g,
+		setter:  setter,
+		deleter: deleter,
+		opts:    opts,
+	}
+	if opts != nil {
+		if opts.BatchSize <= 0 {
+			opts.BatchSize = 1
+		}
+		if opts.FlushInterval <= 0 {
+			opts.FlushInterval = time.Second
+		}
+		opts.Retry = opts.Retry.normalize()
+		w.pending = make(map[string]writeOp)
+		w.notify = make(chan struct{}, 1)
+		w.closeCh = make(chan struct{})
+		w.doneCh = make(chan struct{})
+		go w.flushLoop()
+	}
+	return w
+}
+
+// mode 返回写模式名称
+func (w *writer) mode() string {
+	if w.opts == nil {
+		return "write-through"
+	}
+	return "write-behind"
+}
+
+// set 写入一个键值，同步写模式下直接返回后端存储的错误
+func (w *writer) set(ctx context.Context, key string, value []byte) error {
+	if w.setter == nil {
+		return nil
+	}
+	if w.opts == nil {
+		if err := w.setter.Set(ctx, key, value); err != nil {
+			atomic.AddInt64(&w.errors, 1)
+			return err
+		}
+		return nil
+	}
+	return w.enqueue(writeOp{key: key, value: value})
+}
+
+// delete 删除一个键
+func (w *writer) delete(ctx context.Context, key string) error {
+	if w.deleter == nil {
+		return nil
+	}
+	if w.opts == nil {
+		if err := w.deleter.Delete(ctx, key); err != nil {
+			atomic.AddInt64(&w.errors, 1)
+			return err
+		}
+		return nil
+	}
+	return w.enqueue(writeOp{key: key})
+}
+
+// enqueue 将操作加入异步写队列，同一个键只保留最后一次操作
+func (w *writer) enqueue(op writeOp) error {
+	w.mu.Lock()
+	select {
+	case <-w.closeCh:
+		w.mu.Unlock()
+		return ErrWriteQueueClosed
+	default:
+	}
+	if _, exists := w.pending[op.key]; !exists {
+		if w.opts.QueueSize > 0 && len(w.pending) >= w.opts.QueueSize {
+			w.mu.Unlock()
+			return ErrWriteQueueFull
+		}
+		w.order = append(w.order, op.key)
+	}
+	w.pending[op.key] = op
+	full := len(w.pending) >= w.opts.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// flushLoop 定期或在攒够一批时刷新队列，关闭时刷新剩余数据
+func (w *writer) flushLoop() {
+	defer close(w.doneCh)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush(context.Background())
+		case <-w.notify:
+			w.flush(context.Background())
+		case <-w.closeCh:
+			ctx, cancel := context.WithTimeout(context.Background(), defaultWriteFlushTimeout)
+			w.flush(ctx)
+			cancel()
+			return
+		}
+	}
+}
+
+// flush 按批次写出队列中的全部操作
+func (w *writer) flush(ctx context.Context) {
+	for {
+		batch := w.nextBatch()
+		if len(batch) == 0 {
+			return
+		}
+		atomic.AddInt64(&w.batches, 1)
+		w.writeBatch(ctx, batch)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// nextBatch 从队列头部取出一批操作
+func (w *writer) nextBatch() []writeOp {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(w.order)
+	if n > w.opts.BatchSize {
+		n = w.opts.BatchSize
+	}
+	batch := make([]writeOp, 0, n)
+	for _, key := range w.order[:n] {
+		batch = append(batch, w.pending[key])
+		delete(w.pending, key)
+	}
+	w.order = w.order[n:]
+	return batch
+}
+
+// writeBatch 写出一批操作，支持批量写入时合并所有 Set
+func (w *writer) writeBatch(ctx context.Context, batch []writeOp) {
+	if bs, ok := w.setter.(BatchSetter); ok {
+		values := make(map[string][]byte)
+		var deletes []writeOp
+		for _, op := range batch {
+			if op.value != nil {
+				values[op.key] = op.value
+			} else {
+				deletes = append(deletes, op)
+			}
+		}
+		if len(values) > 0 {
+			err := w.withRetry(ctx, func() error {
+				return bs.SetBatch(ctx, values)
+			})
+			w.record(len(values), err)
+		}
+		batch = deletes
+	}
+	for _, op := range batch {
+		err := w.withRetry(ctx, func() error {
+			if op.value == nil {
+				return w.deleter.Delete(ctx, op.key)
+			}
+			return w.setter.Set(ctx, op.key, op.value)
+		})
+		w.record(1, err)
+	}
+}
+
+// withRetry 按重试策略执行写入
+func (w *writer) withRetry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= w.opts.Retry.MaxAttempts || !w.opts.Retry.Retryable(err) {
+			return err
+		}
+		atomic.AddInt64(&w.retries, 1)
+		timer := time.NewTimer(w.opts.Retry.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// record 记录写入结果
+func (w *writer) record(n int, err error) {
+	if err == nil {
+		atomic.AddInt64(&w.flushed, int64(n))
+		return
+	}
+	atomic.AddInt64(&w.failed, int64(n))
+	logger.L().Error("write-behind failed",
+		zap.String("group", w.group.name),
+		zap.Int("keys", n),
+		zap.Error(err))
+}
+
+// close 关闭异步写队列并等待剩余数据写出，可以重复调用
+func (w *writer) close() {
+	if w.opts == nil {
+		return
+	}
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		close(w.closeCh)
+		w.mu.Unlock()
+	})
+	<-w.doneCh
+}
+
+// stats 返回写统计信息
+func (w *writer) stats() map[string]interface{} {
+	stats := map[string]interface{}{
+		"mode": w.mode(),
+	}
+	if w.opts == nil {
+		stats["errors"] = atomic.LoadInt64(&w.errors)
+		return stats
+	}
+	w.mu.Lock()
+	stats["pending"] = len(w.pending)
+	w.mu.Unlock()
+	stats["flushed"] = atomic.LoadInt64(&w.flushed)
+	stats["failed"] = atomic.LoadInt64(&w.failed)
+	stats["retries"] = atomic.LoadInt64(&w.retries)
+	stats["batches"] = atomic.LoadInt64(&w.batches)
+	return stats
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingStore 记录写入的测试后端存储
+type recordingStore struct {
+	mu       sync.Mutex
+	sets     []string            // 单个写入的键，格式为 key=value
+	batches  []map[string]string // 批量写入的内容
+	deletes  []string
+	failures int // 前几次写入返回错误
+}
+
+func (s *recordingStore) Set(ctx context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("backend unavailable")
+	}
+	s.sets = append(s.sets, key+"="+string(value))
+	return nil
+}
+
+func (s *recordingStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletes = append(s.deletes, key)
+	return nil
+}
+
+// batchStore 支持批量写入的测试后端存储
+type batchStore struct {
+	recordingStore
+}
+
+func (s *batchStore) SetBatch(ctx context.Context, values map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch := make(map[string]string, len(values))
+	for k, v := range values {
+		batch[k] = string(v)
+	}
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func newWriteBehindGroup(name string, store interface {
+	Setter
+	Deleter
+}, opts WriteBehindOptions) *Group {
+	return NewGroup(name, 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}), WithWriteBehind(store, store, opts))
+}
+
+// 测试同一个键的多次写入合并为最后一次，关闭时刷新队列
+func TestWriteBehindCoalesceAndFlushOnClose(t *testing.T) {
+	store := &recordingStore{}
+	g := newWriteBehindGroup("write-behind-coalesce", store, WriteBehindOptions{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+		Retry:         RetryPolicy{MaxAttempts: 1},
+	})
+	ctx := context.Background()
+	for _, v := range []string{"v1", "v2", "v3"} {
+		if err := g.Set(ctx, "k", []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Set(ctx, "gone", []byte("x"))
+	g.Delete(ctx, "gone")
+
+	g.Close()
+	if len(store.sets) != 1 || store.sets[0] != "k=v3" {
+		t.Fatalf("写入应合并为最后一次，实际 %v", store.sets)
+	}
+	if len(store.deletes) != 1 || store.deletes[0] != "gone" {
+		t.Fatalf("删除应覆盖之前的写入，实际删除 %v", store.deletes)
+	}
+}
+
+// 测试支持批量写入的后端按批次写入，每批不超过 BatchSize
+func TestWriteBehindBatchSetter(t *testing.T) {
+	store := &batchStore{}
+	g := newWriteBehindGroup("write-behind-batch", store, WriteBehindOptions{
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		Retry:         RetryPolicy{MaxAttempts: 1},
+	})
+	ctx := context.Background()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		if err := g.Set(ctx, k, []byte(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Close()
+
+	total := 0
+	for _, batch := range store.batches {
+		if len(batch) > 3 {
+			t.Fatalf("批次大小 %d 超过 BatchSize", len(batch))
+		}
+		total += len(batch)
+	}
+	if total != 5 || len(store.sets) != 0 {
+		t.Fatalf("应批量写入 5 个键，实际批量 %d 个，单个 %v", total, store.sets)
+	}
+	if n := g.Stats()["write_flushed"]; n != int64(5) {
+		t.Fatalf("write_flushed = %v", n)
+	}
+}
+
+// 测试写入失败后按重试策略重试
+func TestWriteBehindRetry(t *testing.T) {
+	store := &recordingStore{failures: 2}
+	g := newWriteBehindGroup("write-behind-retry", store, WriteBehindOptions{
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Retry:         RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	if err := g.Set(context.Background(), "k", []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	g.Close()
+
+	stats := g.writer.stats()
+	if stats["retries"] != int64(2) || stats["flushed"] != int64(1) || stats["failed"] != int64(0) {
+		t.Fatalf("统计错误: %v", stats)
+	}
+	if len(store.sets) != 1 {
+		t.Fatalf("重试后应写入成功，实际 %v", store.sets)
+	}
+}
+
+// 测试关闭后入队返回错误，重复关闭不会 panic
+func TestWriteBehindClosed(t *testing.T) {
+	store := &recordingStore{}
+	g := newWriteBehindGroup("write-behind-closed", store, WriteBehindOptions{
+		FlushInterval: time.Hour,
+		Retry:         RetryPolicy{Multiplier: 0.5},
+	})
+	if g.writer.opts.Retry.MaxAttempts != 1 || g.writer.opts.Retry.Multiplier != 1 {
+		t.Fatalf("无效的重试配置未修正: %+v", g.writer.opts.Retry)
+	}
+	g.Close()
+
+	if err := g.writer.set(context.Background(), "k", []byte("v")); !errors.Is(err, ErrWriteQueueClosed) {
+		t.Fatalf("关闭后入队应返回 ErrWriteQueueClosed，得到 %v", err)
+	}
+	g.writer.close()
+}
@@ -0,0 +1,218 @@
+package circuitBreaker
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wsss777/LRUCache/logger"
+	"go.uber.org/zap"
+)
+
+// ErrOpen 熔断器处于打开状态，请求被快速拒绝
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State 熔断器状态
+type State int32
+
+const (
+	Closed   State = iota // 关闭：请求正常通过
+	Open                  // 打开：请求被快速拒绝
+	HalfOpen              // 半开：允许少量探测请求通过
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config 熔断器配置
+type Config struct {
+	FailureThreshold int              // 连续失败多少次后打开
+	OpenTimeout      time.Duration    // 打开多久后进入半开状态
+	HalfOpenMaxCalls int              // 半开状态下同时允许的探测请求数
+	SuccessThreshold int              // 半开状态下连续成功多少次后关闭
+	IsFailure        func(error) bool // 判断错误是否计为失败，nil 表示所有非 nil 错误都计为失败
+}
+
+// DefaultConfig 默认配置
+var DefaultConfig = &Config{
+	FailureThreshold: 5,
+	OpenTimeout:      5 * time.Second,
+	HalfOpenMaxCalls: 1,
+	SuccessThreshold: 1,
+}
+
+// Breaker 熔断器
+type Breaker struct {
+	mu            sync.Mutex
+	name          string
+	config        *Config
+	state         State
+	failures      int       // 关闭状态下的连续失败次数
+	successes     int       // 半开状态下的连续成功次数
+	halfOpenCalls int       // 半开状态下正在进行的探测请求数
+	openedAt      time.Time // 最近一次打开的时间
+	generation    uint64    // 每次状态切换加一，用于忽略旧状态下发起的请求结果
+
+	requests  int64 // 通过的请求数
+	rejects   int64 // 被拒绝的请求数
+	failTotal int64 // 失败总数
+	trips     int64 // 打开次数
+}
+
+type Option func(*Breaker)
+
+// WithConfig 设置配置
+func WithConfig(c *Config) Option {
+	return func(b *Breaker) {
+		b.config = c
+	}
+}
+
+// New 创建熔断器
+func New(name string, opts ...Option) *Breaker {
+	b := &Breaker{
+		name:   name,
+		config: DefaultConfig,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Allow 判断请求能否通过。通过时返回的 done 必须以请求结果调用一次；被拒绝时返回 ErrOpen
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.state == Open && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(HalfOpen)
+	}
+	switch b.state {
+	case Open:
+		atomic.AddInt64(&b.rejects, 1)
+		return nil, ErrOpen
+	case HalfOpen:
+		if b.halfOpenCalls >= b.maxHalfOpenCalls() {
+			atomic.AddInt64(&b.rejects, 1)
+			return nil, ErrOpen
+		}
+		b.halfOpenCalls++
+	}
+
+	atomic.AddInt64(&b.requests, 1)
+	generation := b.generation
+	return func(err error) {
+		b.done(generation, err)
+	}, nil
+}
+
+// Do 在熔断器保护下执行 fn
+func (b *Breaker) Do(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err)
+	return err
+}
+
+// State 返回当前状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.config.OpenTimeout {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Stats 返回熔断器统计信息
+func (b *Breaker) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"state":    b.State().String(),
+		"requests": atomic.LoadInt64(&b.requests),
+		"rejects":  atomic.LoadInt64(&b.rejects),
+		"failures": atomic.LoadInt64(&b.failTotal),
+		"trips":    atomic.LoadInt64(&b.trips),
+	}
+}
+
+// done 记录请求结果
+func (b *Breaker) done(generation uint64, err error) {
+	failed := err != nil && (b.config.IsFailure == nil || b.config.IsFailure(err))
+	if failed {
+		atomic.AddInt64(&b.failTotal, 1)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// 状态已经切换，旧请求的结果不再影响当前状态
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case Closed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.setState(Open)
+		}
+	case HalfOpen:
+		b.halfOpenCalls--
+		if failed {
+			b.setState(Open)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.SuccessThreshold {
+			b.setState(Closed)
+		}
+	}
+}
+
+// setState 切换状态，调用前必须持有锁
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	prev := b.state
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.halfOpenCalls = 0
+	if state == Open {
+		b.openedAt = time.Now()
+		atomic.AddInt64(&b.trips, 1)
+	}
+	logger.L().Info("circuit breaker state changed",
+		zap.String("name", b.name),
+		zap.String("from", prev.String()),
+		zap.String("to", state.String()))
+}
+
+// maxHalfOpenCalls 返回半开状态下允许的探测请求数
+func (b *Breaker) maxHalfOpenCalls() int {
+	if b.config.HalfOpenMaxCalls > 0 {
+		return b.config.HalfOpenMaxCalls
+	}
+	return 1
+}
@@ -0,0 +1,62 @@
+package circuitBreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+// 测试关闭 -> 打开 -> 半开 -> 关闭的状态流转
+func TestBreakerStateTransitions(t *testing.T) {
+	b := New("test", WithConfig(&Config{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		HalfOpenMaxCalls: 1,
+		SuccessThreshold: 1,
+	}))
+
+	for i := 0; i < 2; i++ {
+		b.Do(func() error { return errTest })
+	}
+	if b.State() != Open {
+		t.Fatalf("连续失败后应为打开状态，实际为%s", b.State())
+	}
+	if err := b.Do(func() error { return nil }); !errors.Is(err, ErrOpen) {
+		t.Fatalf("打开状态应快速失败，实际为%v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if b.State() != HalfOpen {
+		t.Fatalf("超时后应为半开状态，实际为%s", b.State())
+	}
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("半开状态应允许探测请求: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatal("半开状态超出探测数量应被拒绝")
+	}
+	done(nil)
+	if b.State() != Closed {
+		t.Fatalf("探测成功后应为关闭状态，实际为%s", b.State())
+	}
+}
+
+// 测试半开状态下探测失败重新打开
+func TestBreakerHalfOpenFailure(t *testing.T) {
+	b := New("test", WithConfig(&Config{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+	}))
+	b.Do(func() error { return errTest })
+	time.Sleep(30 * time.Millisecond)
+	b.Do(func() error { return errTest })
+	if b.State() != Open {
+		t.Fatalf("探测失败后应重新打开，实际为%s", b.State())
+	}
+	if trips := b.Stats()["trips"].(int64); trips != 2 {
+		t.Fatalf("打开次数应为2，实际为%d", trips)
+	}
+}
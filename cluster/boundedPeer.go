@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/wsss777/LRUCache/consistentHash"
+)
+
+// clientPeer Client 及其包装类型实现的全部接口
+type clientPeer interface {
+	Peer
+	FallbackPeer
+	TaggedPeer
+	TagInvalidator
+}
+
+var _ clientPeer = (*Client)(nil)
+var _ clientPeer = (*breakerPeer)(nil)
+var _ clientPeer = (*boundedPeer)(nil)
+
+// boundedPeer 有界负载模式下选出的节点，请求结束后释放该节点的进行中请求计数。
+// 每次 PickPeer 的结果只应用于一次请求
+type boundedPeer struct {
+	clientPeer
+	release func()
+}
+
+// WithBoundedLoad 启用有界负载的一致性哈希，每个节点最多承担 ceil((1+epsilon)·平均负载)
+// 个本节点发出的进行中请求，PickPeer 跳过满载节点，热点键的请求会溢出到哈希环上的后续节点。
+// 只对默认的哈希环生效，与 WithNodeSelector 同时使用时以后者为准
+func WithBoundedLoad(epsilon float64) PickerOption {
+	return func(p *ClientPicker) {
+		cfg := *consistentHash.DefaultConfig
+		cfg.LoadFactor = epsilon
+		p.hashConfig = &cfg
+	}
+}
+
+// pickBounded 按有界负载选择节点，调用前必须持有锁
+func (p *ClientPicker) pickBounded(bs consistentHash.BoundedSelector, key string) (Peer, bool, bool) {
+	addr, release := bs.Acquire(key)
+	peer, ok, isSelf := p.pick(addr)
+	if !ok || isSelf {
+		// 本地处理的请求不经过 Peer，无法得知结束时间，立即释放
+		release()
+		return peer, ok, isSelf
+	}
+	return &boundedPeer{clientPeer: peer.(clientPeer), release: release}, true, false
+}
+
+func (p *boundedPeer) Get(group, key string) ([]byte, error) {
+	defer p.release()
+	return p.clientPeer.Get(group, key)
+}
+
+func (p *boundedPeer) GetWithTags(group, key string) ([]byte, []string, error) {
+	defer p.release()
+	return p.clientPeer.GetWithTags(group, key)
+}
+
+func (p *boundedPeer) GetFallback(group, key string) ([]byte, error) {
+	defer p.release()
+	return p.clientPeer.GetFallback(group, key)
+}
+
+func (p *boundedPeer) Set(ctx context.Context, group, key string, value []byte, tags ...string) error {
+	defer p.release()
+	return p.clientPeer.Set(ctx, group, key, value, tags...)
+}
+
+func (p *boundedPeer) InvalidateTag(group, tag string) (int64, error) {
+	defer p.release()
+	return p.clientPeer.InvalidateTag(group, tag)
+}
+
+func (p *boundedPeer) Delete(group, key string) (bool, error) {
+	defer p.release()
+	return p.clientPeer.Delete(group, key)
+}
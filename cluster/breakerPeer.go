@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/wsss777/LRUCache/circuitBreaker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ Peer = (*breakerPeer)(nil)
+var _ FallbackPeer = (*breakerPeer)(nil)
+var _ TaggedPeer = (*breakerPeer)(nil)
+var _ TagInvalidator = (*breakerPeer)(nil)
+
+// breakerPeer 为 Client 加上熔断保护，熔断打开时请求快速失败并返回 circuitBreaker.ErrOpen
+type breakerPeer struct {
+	*Client
+	breaker *circuitBreaker.Breaker
+}
+
+// WithPeerBreaker 为每个节点启用熔断器，节点连续失败后快速失败，由调用方退化为本地加载。
+// c 为 nil 时使用 circuitBreaker.DefaultConfig
+func WithPeerBreaker(c *circuitBreaker.Config) PickerOption {
+	return func(p *ClientPicker) {
+		if c == nil {
+			c = circuitBreaker.DefaultConfig
+		}
+		cfg := *c
+		isFailure := cfg.IsFailure
+		cfg.IsFailure = func(err error) bool {
+			// 对端明确返回键不存在，说明节点是健康的
+			if status.Code(err) == codes.NotFound {
+				return false
+			}
+			return isFailure == nil || isFailure(err)
+		}
+		p.breakerCfg = &cfg
+	}
+}
+
+func (p *breakerPeer) Get(group, key string) ([]byte, error) {
+	var value []byte
+	err := p.breaker.Do(func() error {
+		var err error
+		value, err = p.Client.Get(group, key)
+		return err
+	})
+	return value, err
+}
+
+func (p *breakerPeer) GetWithTags(group, key string) ([]byte, []string, error) {
+	var value []byte
+	var tags []string
+	err := p.breaker.Do(func() error {
+		var err error
+		value, tags, err = p.Client.GetWithTags(group, key)
+		return err
+	})
+	return value, tags, err
+}
+
+func (p *breakerPeer) GetFallback(group, key string) ([]byte, error) {
+	var value []byte
+	err := p.breaker.Do(func() error {
+		var err error
+		value, err = p.Client.GetFallback(group, key)
+		return err
+	})
+	return value, err
+}
+
+func (p *breakerPeer) Set(ctx context.Context, group, key string, value []byte, tags ...string) error {
+	return p.breaker.Do(func() error {
+		return p.Client.Set(ctx, group, key, value, tags...)
+	})
+}
+
+func (p *breakerPeer) InvalidateTag(group, tag string) (int64, error) {
+	var deleted int64
+	err := p.breaker.Do(func() error {
+		var err error
+		deleted, err = p.Client.InvalidateTag(group, tag)
+		return err
+	})
+	return deleted, err
+}
+
+func (p *breakerPeer) Delete(group, key string) (bool, error) {
+	var deleted bool
+	err := p.breaker.Do(func() error {
+		var err error
+		deleted, err = p.Client.Delete(group, key)
+		return err
+	})
+	return deleted, err
+}
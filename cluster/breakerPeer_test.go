@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/circuitBreaker"
+	pb "github.com/wsss777/LRUCache/pb"
+	"github.com/wsss777/LRUCache/registry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// codeServer 对所有 Get 返回指定状态码的测试服务
+type codeServer struct {
+	pb.UnimplementedWsCacheServer
+	code  atomic.Value // codes.Code
+	calls int32
+}
+
+func (s *codeServer) Get(ctx context.Context, req *pb.Request) (*pb.ResponseForGet, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return nil, status.Error(s.code.Load().(codes.Code), req.Key)
+}
+
+// startCodeServer 启动测试服务，返回服务地址
+func startCodeServer(t *testing.T, code codes.Code) (*codeServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &codeServer{}
+	srv.code.Store(code)
+	gs := grpc.NewServer()
+	pb.RegisterWsCacheServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+	return srv, lis.Addr().String()
+}
+
+// 测试对端返回 NotFound 不打开熔断器，其他错误达到阈值后快速失败
+func TestPeerBreaker(t *testing.T) {
+	srv, addr := startCodeServer(t, codes.NotFound)
+	p, err := newClientPicker("self:1", WithPeerBreaker(&circuitBreaker.Config{
+		FailureThreshold: 2, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.dial = func(addr string) (*Client, error) { return dialClient(addr) }
+	defer p.Close()
+	p.set(registry.Instance{Addr: addr, Weight: 1})
+	p.mu.RLock()
+	peer, _, _ := p.pick(addr)
+	p.mu.RUnlock()
+
+	for i := 0; i < 3; i++ {
+		if _, err := peer.Get("g", "k"); status.Code(err) != codes.NotFound {
+			t.Fatalf("should return NotFound, got %v", err)
+		}
+	}
+	if state := p.Stats()["breaker_"+addr]; state != "closed" {
+		t.Fatalf("NotFound should not trip the breaker, state %v", state)
+	}
+
+	srv.code.Store(codes.Unavailable)
+	for i := 0; i < 2; i++ {
+		peer.Get("g", "k")
+	}
+	if state := p.Stats()["breaker_"+addr]; state != "open" {
+		t.Fatalf("breaker should be open after 2 failures, state %v", state)
+	}
+	calls := atomic.LoadInt32(&srv.calls)
+	if _, err := peer.Get("g", "k"); !errors.Is(err, circuitBreaker.ErrOpen) {
+		t.Fatalf("open breaker should fail fast, got %v", err)
+	}
+	if n := atomic.LoadInt32(&srv.calls); n != calls {
+		t.Fatalf("open breaker should not call the peer, calls %d -> %d", calls, n)
+	}
+}
+
+// 测试未指定熔断配置时使用默认配置
+func TestPeerBreakerNilConfig(t *testing.T) {
+	p, err := newClientPicker("self:1", WithPeerBreaker(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if p.breakerCfg == nil || p.breakerCfg.FailureThreshold != circuitBreaker.DefaultConfig.FailureThreshold {
+		t.Fatalf("breaker config should default to circuitBreaker.DefaultConfig, got %+v", p.breakerCfg)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 type Client struct {
@@ -22,6 +23,16 @@ type Client struct {
 }
 
 var _ Peer = (*Client)(nil)
+var _ FallbackPeer = (*Client)(nil)
+var _ TaggedPeer = (*Client)(nil)
+var _ TagInvalidator = (*Client)(nil)
+var _ Migrator = (*Client)(nil)
+
+// fallbackMetadataKey 标记请求由接替节点代替所有者加载
+const fallbackMetadataKey = "wscache-fallback"
+
+// peerMetadataKey 标记请求来自其他节点
+const peerMetadataKey = "wscache-from-peer"
 
 func NewClient(addr string, svcName string, etcdCli *clientv3.Client) (*Client, error) {
 	var err error
@@ -34,42 +45,90 @@ func NewClient(addr string, svcName string, etcdCli *clientv3.Client) (*Client,
 			return nil, fmt.Errorf("failed to create etcd client error: %v", err)
 		}
 	}
-	conn, err := grpc.Dial(addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	client, err := dialClient(addr,
 		grpc.WithBlock(),
 		grpc.WithTimeout(10*time.Second), // 超时控制
-		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
 	)
+	if err != nil {
+		return nil, err
+	}
+	client.svcName = svcName
+	client.etcdCli = etcdCli
+	return client, nil
+}
+
+// dialClient 连接节点的 gRPC 服务，不依赖 etcd
+func dialClient(addr string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
+	}, opts...)
+	conn, err := grpc.Dial(addr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial server : %v", err)
 	}
-	grpcClient := pb.NewWsCacheClient(conn)
-	client := &Client{
+	return &Client{
 		addr:    addr,
-		svcName: svcName,
-		etcdCli: etcdCli,
 		conn:    conn,
-		grpcCli: grpcClient,
-	}
-	return client, nil
+		grpcCli: pb.NewWsCacheClient(conn),
+	}, nil
 }
+
 func (c *Client) Get(group, key string) ([]byte, error) {
+	value, _, err := c.GetWithTags(group, key)
+	return value, err
+}
+
+// GetWithTags 获取值及其标签
+func (c *Client) GetWithTags(group, key string) ([]byte, []string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	// 标记为节点间的读取，对端未命中时直接访问所有者，不再转发给其他副本
+	ctx = metadata.AppendToOutgoingContext(ctx, peerMetadataKey, "1")
+
+	resp, err := c.grpcCli.Get(ctx, &pb.Request{
+		Group: group,
+		Key:   key,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get value from wsCache: %w", err)
+	}
+
+	return resp.GetValue(), resp.GetTags(), nil
+}
+
+// GetFallback 请求对端作为接替节点直接从数据源加载，不再转发给所有者
+func (c *Client) GetFallback(group, key string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, fallbackMetadataKey, "1")
 
 	resp, err := c.grpcCli.Get(ctx, &pb.Request{
 		Group: group,
 		Key:   key,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get value from wsCache: %v", err)
+		return nil, fmt.Errorf("failed to get fallback value from wsCache: %w", err)
 	}
 
 	return resp.GetValue(), nil
 }
+
+// IsPeerRequest 判断收到的请求是否来自其他节点
+func IsPeerRequest(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(peerMetadataKey)) > 0
+}
+
+// IsFallbackRequest 判断收到的请求是否为接替加载请求
+func IsFallbackRequest(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(fallbackMetadataKey)) > 0
+}
 func (c *Client) Delete(group, key string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, peerMetadataKey, "1")
 
 	resp, err := c.grpcCli.Delete(ctx, &pb.Request{
 		Group: group,
@@ -81,11 +140,13 @@ func (c *Client) Delete(group, key string) (bool, error) {
 
 	return resp.GetValue(), nil
 }
-func (c *Client) Set(ctx context.Context, group, key string, value []byte) error {
+func (c *Client) Set(ctx context.Context, group, key string, value []byte, tags ...string) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, peerMetadataKey, "1")
 	resp, err := c.grpcCli.Set(ctx, &pb.Request{
 		Group: group,
 		Key:   key,
 		Value: value,
+		Tags:  tags,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to set value to wsCache: %v", err)
@@ -95,6 +156,50 @@ func (c *Client) Set(ctx context.Context, group, key string, value []byte) error
 
 	return nil
 }
+
+// InvalidateTag 请求对端删除带有指定标签的所有键，返回删除的键数
+func (c *Client) InvalidateTag(group, tag string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, peerMetadataKey, "1")
+
+	resp, err := c.grpcCli.InvalidateTag(ctx, &pb.TagRequest{
+		Group: group,
+		Tag:   tag,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate tag in wsCache: %w", err)
+	}
+
+	return resp.GetDeleted(), nil
+}
+
+// HotKeys 获取对端节点组内访问最多的键
+func (c *Client) HotKeys(group string, limit int) ([]*pb.HotKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	resp, err := c.grpcCli.HotKeys(ctx, &pb.HotKeysRequest{
+		Group: group,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hot keys from wsCache: %w", err)
+	}
+
+	return resp.GetKeys(), nil
+}
+
+// Migrate 打开向对端迁移缓存条目的双向流，ctx 结束时流被关闭
+func (c *Client) Migrate(ctx context.Context) (pb.WsCache_MigrateClient, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, peerMetadataKey, "1")
+	stream, err := c.grpcCli.Migrate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrate stream: %w", err)
+	}
+	return stream, nil
+}
+
 func (c *Client) Close() error {
 	if c.conn != nil {
 		return c.conn.Close()
@@ -8,8 +8,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/wsss777/LRUCache/circuitBreaker"
 	"github.com/wsss777/LRUCache/consistentHash"
 	"github.com/wsss777/LRUCache/logger"
+	pb "github.com/wsss777/LRUCache/pb"
 	"github.com/wsss777/LRUCache/registry"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
@@ -20,27 +22,93 @@ const defaultSvcName = "ws-cache"
 // PeerPicker 定义了peer选择器的接口
 type PeerPicker interface {
 	PickPeer(key string) (peer Peer, ok bool, self bool)
+	PickPeers(key string, n int) []PickedPeer
 	Close() error
 }
 
+// PickedPeer 副本选择结果，Self 为 true 时表示当前节点，此时 Peer 为 nil
+type PickedPeer struct {
+	Addr string
+	Peer Peer
+	Self bool
+}
+
+// FallbackPicker 可选接口，所有者节点不可用时选出唯一的接替节点（哈希环上的后继节点），
+// 使各节点对同一个键选出相同的接替者
+type FallbackPicker interface {
+	PickFallback(key string) (peer Peer, ok bool, self bool)
+}
+
+// FallbackPeer 可选接口，请求接替节点代替所有者从数据源加载
+type FallbackPeer interface {
+	GetFallback(group string, key string) ([]byte, error)
+}
+
+// ReadPicker 可选接口，为读请求选择键的副本，本地可用区的副本排在前面
+type ReadPicker interface {
+	PickReadPeers(key string, n int) []PickedPeer
+}
+
+// PeerLister 可选接口，列出除自身外的所有节点，用于广播
+type PeerLister interface {
+	Peers() []Peer
+}
+
+// TaggedPeer 可选接口，获取值的同时获取其标签
+type TaggedPeer interface {
+	GetWithTags(group string, key string) ([]byte, []string, error)
+}
+
+// TagInvalidator 可选接口，请求对端删除带有指定标签的所有键
+type TagInvalidator interface {
+	InvalidateTag(group string, tag string) (int64, error)
+}
+
+// Migrator 可选接口，打开向对端迁移缓存条目的流
+type Migrator interface {
+	Migrate(ctx context.Context) (pb.WsCache_MigrateClient, error)
+}
+
+// MembershipNotifier 可选接口，有节点加入或权重变化导致键的归属移动到该节点时通知订阅者
+type MembershipNotifier interface {
+	OnPeerJoin(fn func(addr string, peer Peer))
+}
+
 // Peer 定义了缓存节点的接口
 type Peer interface {
 	Get(group string, key string) ([]byte, error)
-	Set(ctx context.Context, group string, key string, value []byte) error
+	Set(ctx context.Context, group string, key string, value []byte, tags ...string) error
 	Delete(group string, key string) (bool, error)
 	Close() error
 }
 
+var _ FallbackPicker = (*ClientPicker)(nil)
+var _ PeerLister = (*ClientPicker)(nil)
+var _ MembershipNotifier = (*ClientPicker)(nil)
+var _ ReadPicker = (*ClientPicker)(nil)
+
 // ClientPicker 实现了PeerPicker接口
 type ClientPicker struct {
-	selfAddr string
-	svcName  string
-	mu       sync.RWMutex
-	consHash *consistentHash.Map
-	clients  map[string]*Client
-	etcdCli  *clientv3.Client
-	ctx      context.Context
-	cancel   context.CancelFunc
+	selfAddr   string
+	selfWeight int    // 自身权重，应与注册到etcd的权重一致
+	selfZone   string // 自身所在的可用区，应与注册到etcd的可用区一致
+	svcName    string
+	mu         sync.RWMutex
+	selector   consistentHash.NodeSelector // 节点选择策略
+	hashConfig *consistentHash.Config      // 哈希环配置，未指定选择策略时使用，nil表示使用默认配置
+	clients    map[string]*Client
+	dial       func(addr string) (*Client, error) // 创建节点客户端
+	etcdCli    *clientv3.Client                   // 服务发现使用的etcd客户端，静态节点列表时为nil
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	breakerCfg *circuitBreaker.Config             // 节点熔断配置，nil表示不启用
+	breakers   map[string]*circuitBreaker.Breaker // 每个节点的熔断器
+	weights    map[string]int                     // 每个节点的权重
+	zones      map[string]string                  // 每个节点所在的可用区
+	metas      map[string]registry.Instance       // 从元数据 key 读到的节点信息，可能早于服务 key 到达
+
+	listeners []func(addr string, peer Peer) // 节点加入的订阅者
 }
 
 // PickerOption 定义配置选项
@@ -53,6 +121,28 @@ func WithServiceName(name string) PickerOption {
 	}
 }
 
+// WithWeight 设置自身在哈希环上的权重，应与注册时使用的权重一致
+func WithWeight(weight int) PickerOption {
+	return func(p *ClientPicker) {
+		p.selfWeight = weight
+	}
+}
+
+// WithZone 设置自身所在的可用区，应与注册时使用的可用区一致
+func WithZone(zone string) PickerOption {
+	return func(p *ClientPicker) {
+		p.selfZone = zone
+	}
+}
+
+// WithNodeSelector 设置节点选择策略，如 consistentHash.NewRendezvous()、consistentHash.NewMaglev(0)，
+// 默认使用一致性哈希环。集群中所有节点必须使用相同的策略
+func WithNodeSelector(selector consistentHash.NodeSelector) PickerOption {
+	return func(p *ClientPicker) {
+		p.selector = selector
+	}
+}
+
 // PrintPeers 打印当前已发现的节点（调试用）
 func (p *ClientPicker) PrintPeers() {
 	p.mu.RLock()
@@ -60,42 +150,70 @@ func (p *ClientPicker) PrintPeers() {
 
 	log.Printf("当前已发现的节点:")
 	for addr := range p.clients {
-		log.Printf("- %s", addr)
+		log.Printf("- %s (weight=%d)", addr, p.weights[addr])
 	}
 }
 
 // NewClientPicker 创建新的ClientPicker实例
 func NewClientPicker(addr string, opts ...PickerOption) (*ClientPicker, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	picker := &ClientPicker{
-		selfAddr: addr,
-		svcName:  defaultSvcName,
-		clients:  make(map[string]*Client),
-		consHash: consistentHash.New(),
-		ctx:      ctx,
-		cancel:   cancel,
-	}
-	for _, opt := range opts {
-		opt(picker)
+	picker, err := newClientPicker(addr, opts...)
+	if err != nil {
+		return nil, err
 	}
 	cli, err := clientv3.New(clientv3.Config{
 		Endpoints:   registry.DefaultConfig.Endpoints,
 		DialTimeout: registry.DefaultConfig.DialTimeout,
 	})
 	if err != nil {
-		cancel()
+		picker.cancel()
 		return nil, fmt.Errorf("failed to create etcd client: %v", err)
 	}
 	picker.etcdCli = cli
+	picker.dial = func(addr string) (*Client, error) {
+		return NewClient(addr, picker.svcName, cli)
+	}
 
 	if err := picker.startServiceDiscovery(); err != nil {
-		cancel()
+		picker.cancel()
 		cli.Close()
 		return nil, fmt.Errorf("failed to start service discovery: %v", err)
 	}
 	return picker, nil
 }
 
+// newClientPicker 创建只包含自身的ClientPicker，不启动服务发现
+func newClientPicker(addr string, opts ...PickerOption) (*ClientPicker, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	picker := &ClientPicker{
+		selfAddr:   addr,
+		selfWeight: 1,
+		svcName:    defaultSvcName,
+		clients:    make(map[string]*Client),
+		breakers:   make(map[string]*circuitBreaker.Breaker),
+		weights:    make(map[string]int),
+		zones:      make(map[string]string),
+		metas:      make(map[string]registry.Instance),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	for _, opt := range opts {
+		opt(picker)
+	}
+	if picker.selector == nil {
+		if picker.hashConfig != nil {
+			picker.selector = consistentHash.New(consistentHash.WithConfig(picker.hashConfig))
+		} else {
+			picker.selector = consistentHash.New()
+		}
+	}
+	// 自身也参与选择，保证所有节点的选择结果一致
+	if err := picker.addNode(addr, picker.selfWeight); err != nil {
+		cancel()
+		return nil, err
+	}
+	return picker, nil
+}
+
 // startServiceDiscovery 启动服务发现
 func (p *ClientPicker) startServiceDiscovery() error {
 	// 先进行全量更新
@@ -107,10 +225,11 @@ func (p *ClientPicker) startServiceDiscovery() error {
 	return nil
 }
 
-// watchServiceChanges 监听服务实例变化
+// watchServiceChanges 监听服务实例及其元数据的变化
 func (p *ClientPicker) watchServiceChanges() {
 	watcher := clientv3.NewWatcher(p.etcdCli)
 	watchChan := watcher.Watch(p.ctx, "/services/"+p.svcName, clientv3.WithPrefix())
+	metaChan := watcher.Watch(p.ctx, registry.MetadataPrefix(p.svcName), clientv3.WithPrefix())
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -118,6 +237,8 @@ func (p *ClientPicker) watchServiceChanges() {
 			return
 		case resp := <-watchChan:
 			p.handleWatchEvents(resp.Events)
+		case resp := <-metaChan:
+			p.handleWatchEvents(resp.Events)
 		}
 	}
 }
@@ -126,19 +247,27 @@ func (p *ClientPicker) watchServiceChanges() {
 func (p *ClientPicker) handleWatchEvents(events []*clientv3.Event) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	metaPrefix := registry.MetadataPrefix(p.svcName)
 	for _, event := range events {
-		addr := string(event.Kv.Value)
-		if addr == p.selfAddr {
+		key := string(event.Kv.Key)
+		if strings.HasPrefix(key, metaPrefix) {
+			p.handleMetadataEvent(event, strings.TrimPrefix(key, metaPrefix))
 			continue
 		}
 		switch event.Type {
 		case clientv3.EventTypePut:
-			if _, exists := p.clients[addr]; !exists {
-				p.set(addr)
-				logger.L().Info("New service discovered",
-					zap.String("addr", addr))
+			ins := p.instanceOf(event.Kv.Value)
+			if ins.Addr == "" || ins.Addr == p.selfAddr {
+				continue
 			}
+			p.upsert(ins)
 		case clientv3.EventTypeDelete:
+			// 删除事件不携带值，从key中解析地址
+			addr := parseAddrFromKey(key, p.svcName)
+			if addr == p.selfAddr {
+				continue
+			}
+			delete(p.metas, addr)
 			if client, exists := p.clients[addr]; exists {
 				client.Close()
 				p.remove(addr)
@@ -150,10 +279,60 @@ func (p *ClientPicker) handleWatchEvents(events []*clientv3.Event) {
 	}
 }
 
+// handleMetadataEvent 处理元数据的变化。元数据与服务 key 的事件可能以任意顺序到达，
+// 节点尚未发现时只记录元数据，等服务 key 到达后再使用
+func (p *ClientPicker) handleMetadataEvent(event *clientv3.Event, addr string) {
+	if addr == "" || addr == p.selfAddr {
+		return
+	}
+	if event.Type == clientv3.EventTypeDelete {
+		delete(p.metas, addr)
+		return
+	}
+	ins := registry.ParseInstance(event.Kv.Value)
+	ins.Addr = addr
+	p.metas[addr] = ins
+	if _, exists := p.clients[addr]; exists {
+		p.upsert(ins)
+	}
+}
+
+// instanceOf 解析服务 key 的值，并合并单独保存的元数据
+func (p *ClientPicker) instanceOf(value []byte) registry.Instance {
+	ins := registry.ParseInstance(value)
+	if meta, ok := p.metas[ins.Addr]; ok {
+		ins.Weight, ins.Zone = meta.Weight, meta.Zone
+	}
+	return ins
+}
+
+// upsert 添加新节点，或更新已有节点的权重和可用区
+func (p *ClientPicker) upsert(ins registry.Instance) {
+	if _, exists := p.clients[ins.Addr]; !exists {
+		p.set(ins)
+		logger.L().Info("New service discovered",
+			zap.String("addr", ins.Addr),
+			zap.Int("weight", ins.Weight),
+			zap.String("zone", ins.Zone))
+		p.notifyJoin(ins.Addr)
+		return
+	}
+	// 可用区只影响副本放置，不改变键的所有者
+	p.setZone(ins.Addr, ins.Zone)
+	if p.weights[ins.Addr] != ins.Weight {
+		p.setWeight(ins.Addr, ins.Weight)
+		p.notifyJoin(ins.Addr)
+	}
+}
+
 // fetchAllServices 获取所有服务实例
 func (p *ClientPicker) fetchAllServices() error {
 	ctx, cancel := context.WithTimeout(p.ctx, 3*time.Second)
 	defer cancel()
+	metaResp, err := p.etcdCli.Get(ctx, registry.MetadataPrefix(p.svcName), clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("failed to get service metadata from etcd: %v", err)
+	}
 	resp, err := p.etcdCli.Get(ctx, "/services/"+p.svcName, clientv3.WithPrefix())
 	if err != nil {
 		return fmt.Errorf("failed to get all services from etcd: %v", err)
@@ -161,22 +340,42 @@ func (p *ClientPicker) fetchAllServices() error {
 	}
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	metaPrefix := registry.MetadataPrefix(p.svcName)
+	for _, kv := range metaResp.Kvs {
+		ins := registry.ParseInstance(kv.Value)
+		ins.Addr = strings.TrimPrefix(string(kv.Key), metaPrefix)
+		p.metas[ins.Addr] = ins
+	}
 	for _, kv := range resp.Kvs {
-		addr := string(kv.Value)
-		if addr != "" && addr != p.selfAddr {
-			p.set(addr)
+		ins := p.instanceOf(kv.Value)
+		if ins.Addr != "" && ins.Addr != p.selfAddr {
+			p.set(ins)
 			logger.L().Info("New service discovered",
-				zap.String("addr", addr))
+				zap.String("addr", ins.Addr),
+				zap.Int("weight", ins.Weight),
+				zap.String("zone", ins.Zone))
 		}
 	}
 	return nil
 }
 
 // set 添加服务实例
-func (p *ClientPicker) set(addr string) {
-	if client, err := NewClient(addr, p.svcName, p.etcdCli); err == nil {
-		p.consHash.Add(addr)
+func (p *ClientPicker) set(ins registry.Instance) {
+	addr, weight := ins.Addr, ins.Weight
+	if client, err := p.dial(addr); err == nil {
+		if err := p.addNode(addr, weight); err != nil {
+			client.Close()
+			logger.L().Error("failed to add node to hash ring",
+				zap.String("addr", addr),
+				zap.Error(err))
+			return
+		}
 		p.clients[addr] = client
+		p.weights[addr] = weight
+		p.zones[addr] = ins.Zone
+		if p.breakerCfg != nil {
+			p.breakers[addr] = circuitBreaker.New("peer:"+addr, circuitBreaker.WithConfig(p.breakerCfg))
+		}
 		logger.L().Info("successfully created client ",
 			zap.String("addr", addr))
 
@@ -189,28 +388,185 @@ func (p *ClientPicker) set(addr string) {
 	}
 }
 
+// OnPeerJoin 订阅节点加入事件，fn 在单独的协程中调用
+func (p *ClientPicker) OnPeerJoin(fn func(addr string, peer Peer)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, fn)
+}
+
+// notifyJoin 通知订阅者有节点加入，调用前必须持有锁
+func (p *ClientPicker) notifyJoin(addr string) {
+	client, exists := p.clients[addr]
+	if !exists {
+		return
+	}
+	for _, fn := range p.listeners {
+		go fn(addr, client)
+	}
+}
+
+// setWeight 更新已有服务实例的权重
+func (p *ClientPicker) setWeight(addr string, weight int) {
+	if err := p.addNode(addr, weight); err != nil {
+		logger.L().Error("failed to update node weight",
+			zap.String("addr", addr),
+			zap.Error(err))
+		return
+	}
+	p.weights[addr] = weight
+	logger.L().Info("Service weight updated",
+		zap.String("addr", addr),
+		zap.Int("weight", weight))
+}
+
+// setZone 更新已有服务实例的可用区
+func (p *ClientPicker) setZone(addr, zone string) {
+	if p.zones[addr] == zone {
+		return
+	}
+	p.zones[addr] = zone
+	logger.L().Info("Service zone updated",
+		zap.String("addr", addr),
+		zap.String("zone", zone))
+}
+
+// zoneOf 返回节点所在的可用区，调用前必须持有锁
+func (p *ClientPicker) zoneOf(addr string) string {
+	if addr == p.selfAddr {
+		return p.selfZone
+	}
+	return p.zones[addr]
+}
+
+// addNode 按权重将节点加入选择器，选择器不支持权重时忽略权重
+func (p *ClientPicker) addNode(addr string, weight int) error {
+	if ws, ok := p.selector.(consistentHash.WeightedSelector); ok {
+		return ws.AddWeighted(addr, weight)
+	}
+	return p.selector.Add(addr)
+}
+
+// bounded 返回启用了有界负载模式的选择器
+func (p *ClientPicker) bounded() (consistentHash.BoundedSelector, bool) {
+	bs, ok := p.selector.(consistentHash.BoundedSelector)
+	if !ok || !bs.Bounded() {
+		return nil, false
+	}
+	return bs, true
+}
+
 // remove 移除服务实例
 func (p *ClientPicker) remove(addr string) {
-	p.consHash.Remove(addr)
+	p.selector.Remove(addr)
 	delete(p.clients, addr)
+	delete(p.breakers, addr)
+	delete(p.weights, addr)
+	delete(p.zones, addr)
 }
 
 // PickPeer 选择peer节点
 func (p *ClientPicker) PickPeer(key string) (Peer, bool, bool) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	if addr := p.consHash.Get(key); addr != "" {
-		if client, exists := p.clients[addr]; exists {
+	if bs, ok := p.bounded(); ok {
+		return p.pickBounded(bs, key)
+	}
+	return p.pick(p.selector.Get(key))
+}
 
-			return client, true, addr == p.selfAddr
+// PickPeers 按哈希环顺时针顺序选择键的 n 个不同节点，第一个为所有者，用于多副本和故障转移。
+// 节点标注了可用区时优先选择不同可用区的节点
+func (p *ClientPicker) PickPeers(key string, n int) []PickedPeer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.picked(consistentHash.GetNZones(p.selector, key, n, p.zoneOf))
+}
+
+// PickReadPeers 与 PickPeers 选择相同的副本，但本地可用区的副本排在前面，
+// 读请求按顺序尝试即可优先访问本地可用区，减少跨可用区的延迟和流量
+func (p *ClientPicker) PickReadPeers(key string, n int) []PickedPeer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	addrs := consistentHash.GetNZones(p.selector, key, n, p.zoneOf)
+	return p.picked(consistentHash.PreferZone(addrs, p.selfZone, p.zoneOf))
+}
+
+// picked 将地址转换为选择结果，跳过已不存在的节点，调用前必须持有锁
+func (p *ClientPicker) picked(addrs []string) []PickedPeer {
+	peers := make([]PickedPeer, 0, len(addrs))
+	for _, addr := range addrs {
+		peer, ok, isSelf := p.pick(addr)
+		if !ok {
+			continue
+		}
+		peers = append(peers, PickedPeer{Addr: addr, Peer: peer, Self: isSelf})
+	}
+	return peers
+}
+
+// PickFallback 选择所有者的后继节点
+func (p *ClientPicker) PickFallback(key string) (Peer, bool, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pick(consistentHash.Successor(p.selector, key))
+}
+
+// pick 根据地址返回对应的peer，调用前必须持有锁
+func (p *ClientPicker) pick(addr string) (Peer, bool, bool) {
+	if addr == "" {
+		return nil, false, false
+	}
+	if addr == p.selfAddr {
+		return nil, true, true
+	}
+	if client, exists := p.clients[addr]; exists {
+		if breaker, ok := p.breakers[addr]; ok {
+			return &breakerPeer{Client: client, breaker: breaker}, true, false
 		}
+		return client, true, false
 	}
 	return nil, false, false
 }
 
+// Peers 返回除自身外的所有节点
+func (p *ClientPicker) Peers() []Peer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	peers := make([]Peer, 0, len(p.clients))
+	for addr := range p.clients {
+		if peer, ok, isSelf := p.pick(addr); ok && !isSelf {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// Stats 返回节点统计信息，包括每个节点的熔断器状态
+func (p *ClientPicker) Stats() map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	stats := map[string]interface{}{
+		"peers": len(p.clients),
+		"zone":  p.selfZone,
+	}
+	if bs, ok := p.bounded(); ok {
+		for addr, n := range bs.Loads() {
+			stats["inflight_"+addr] = n
+		}
+	}
+	for addr, breaker := range p.breakers {
+		stats["breaker_"+addr] = breaker.State().String()
+	}
+	return stats
+}
+
 // Close 关闭所有资源
 func (p *ClientPicker) Close() error {
 	p.cancel()
+	if s, ok := p.selector.(interface{ Stop() }); ok {
+		s.Stop()
+	}
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -221,8 +577,10 @@ func (p *ClientPicker) Close() error {
 		}
 	}
 
-	if err := p.etcdCli.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("failed to close etcd client: %v", err))
+	if p.etcdCli != nil {
+		if err := p.etcdCli.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close etcd client: %v", err))
+		}
 	}
 
 	if len(errs) > 0 {
@@ -0,0 +1,113 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/registry"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// 测试读取时本地可用区的副本排在前面，副本分散在不同可用区
+func TestPickReadPeersPrefersLocalZone(t *testing.T) {
+	p, err := newClientPicker("self:1", WithZone("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.dial = func(addr string) (*Client, error) { return dialClient(addr) }
+	defer p.Close()
+
+	zones := map[string]string{"self:1": "a", "a:2": "a", "b:1": "b", "b:2": "b", "c:1": "c"}
+	for addr, zone := range zones {
+		if addr != "self:1" {
+			p.set(registry.Instance{Addr: addr, Weight: 1, Zone: zone})
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		peers := p.PickReadPeers(key, 2)
+		if len(peers) != 2 {
+			t.Fatalf("key %s: got %d replicas", key, len(peers))
+		}
+		if zones[peers[0].Addr] == zones[peers[1].Addr] {
+			t.Fatalf("key %s: replicas %s and %s share a zone", key, peers[0].Addr, peers[1].Addr)
+		}
+		// 副本中有本地可用区的节点时必须排在第一位
+		if zones[peers[1].Addr] == "a" {
+			t.Fatalf("key %s: local replica %s is not read first", key, peers[1].Addr)
+		}
+		// 与写入使用的副本相同
+		written := p.PickPeers(key, 2)
+		if written[0].Addr != peers[0].Addr && written[0].Addr != peers[1].Addr {
+			t.Fatalf("key %s: owner %s missing from read replicas", key, written[0].Addr)
+		}
+	}
+}
+
+// putEvent 构造 etcd 的写入事件
+func putEvent(key, value string) *clientv3.Event {
+	return &clientv3.Event{Type: clientv3.EventTypePut, Kv: &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value)}}
+}
+
+// 测试服务 key 只保存地址，元数据单独保存，两者以任意顺序到达时都能得到正确的权重和可用区
+func TestWatchServiceMetadata(t *testing.T) {
+	p, err := newClientPicker("self:1", WithServiceName("meta-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.dial = func(addr string) (*Client, error) { return dialClient(addr) }
+	defer p.Close()
+
+	joined := make(chan string, 4)
+	p.OnPeerJoin(func(addr string, peer Peer) { joined <- addr })
+
+	meta := func(addr string, weight int, zone string) string {
+		value, _ := json.Marshal(registry.Instance{Addr: addr, Weight: weight, Zone: zone})
+		return string(value)
+	}
+
+	// 元数据先到达
+	p.handleWatchEvents([]*clientv3.Event{
+		putEvent(registry.MetadataKey("meta-test", "a:1"), meta("a:1", 3, "za")),
+		putEvent("/services/meta-test/a:1", "a:1"),
+	})
+	// 服务 key 先到达，此时按旧版本节点处理，权重为 1
+	p.handleWatchEvents([]*clientv3.Event{putEvent("/services/meta-test/b:1", "b:1")})
+	if p.weights["b:1"] != 1 {
+		t.Fatalf("weight of b:1 = %d before metadata, want 1", p.weights["b:1"])
+	}
+	p.handleWatchEvents([]*clientv3.Event{putEvent(registry.MetadataKey("meta-test", "b:1"), meta("b:1", 2, "zb"))})
+
+	want := map[string]struct {
+		weight int
+		zone   string
+	}{"a:1": {3, "za"}, "b:1": {2, "zb"}}
+	for addr, w := range want {
+		if p.weights[addr] != w.weight || p.zones[addr] != w.zone {
+			t.Errorf("%s: weight %d zone %q, want %d %q", addr, p.weights[addr], p.zones[addr], w.weight, w.zone)
+		}
+	}
+	// a:1 加入一次，b:1 加入和权重变化各一次，通知是异步的
+	for i := 0; i < 3; i++ {
+		select {
+		case <-joined:
+		case <-time.After(time.Second):
+			t.Fatalf("got %d join notifications, want 3", i)
+		}
+	}
+
+	p.handleWatchEvents([]*clientv3.Event{{
+		Type: clientv3.EventTypeDelete,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/services/meta-test/a:1")},
+	}})
+	if _, ok := p.clients["a:1"]; ok {
+		t.Error("a:1 should be removed")
+	}
+	if _, ok := p.metas["a:1"]; ok {
+		t.Error("metadata of a:1 should be removed")
+	}
+}
@@ -0,0 +1,65 @@
+package cluster
+
+import (
+	"github.com/wsss777/LRUCache/logger"
+	"github.com/wsss777/LRUCache/registry"
+	"go.uber.org/zap"
+)
+
+// StaticPicker 使用固定节点列表的选择器，不依赖etcd，路由和自身识别与 ClientPicker 相同，
+// 适用于小规模部署和测试。所有节点应配置相同的节点列表，列表变化时通过 Set 更新
+type StaticPicker struct {
+	*ClientPicker
+}
+
+var _ PeerPicker = (*StaticPicker)(nil)
+
+// NewStaticPicker 创建使用固定节点列表的选择器，addrs 可以包含自身。
+// 支持与 NewClientPicker 相同的选项。列表中的节点权重均为1，自身也应使用默认权重，WithServiceName 不起作用
+func NewStaticPicker(addr string, addrs []string, opts ...PickerOption) (*StaticPicker, error) {
+	picker, err := newClientPicker(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// 不等待连接建立，节点可以按任意顺序启动
+	picker.dial = func(addr string) (*Client, error) {
+		return dialClient(addr)
+	}
+	p := &StaticPicker{ClientPicker: picker}
+	p.Set(addrs...)
+	return p, nil
+}
+
+// Set 用 addrs 替换节点列表，自身始终参与选择，不必包含在内。
+// 新加入的节点会通知 OnPeerJoin 的订阅者
+func (p *StaticPicker) Set(addrs ...string) {
+	want := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		if addr != "" && addr != p.selfAddr {
+			want[addr] = struct{}{}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for addr, client := range p.clients {
+		if _, ok := want[addr]; !ok {
+			client.Close()
+			p.remove(addr)
+			logger.L().Info("Static peer removed",
+				zap.String("addr", addr))
+		}
+	}
+	for _, addr := range addrs {
+		if _, ok := want[addr]; !ok {
+			continue
+		}
+		if _, exists := p.clients[addr]; exists {
+			continue
+		}
+		p.set(registry.Instance{Addr: addr, Weight: 1})
+		logger.L().Info("Static peer added",
+			zap.String("addr", addr))
+		p.notifyJoin(addr)
+	}
+}
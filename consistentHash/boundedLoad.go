@@ -0,0 +1,84 @@
+package consistentHash
+
+import (
+	"math"
+	"sync"
+
+	"github.com/wsss777/LRUCache/hashing"
+)
+
+// Bounded 是否启用有界负载模式
+func (m *Map) Bounded() bool {
+	return m.config.LoadFactor > 0
+}
+
+// Acquire 为键分配一个节点并将该节点的进行中请求数加一，请求结束后必须调用 release。
+// 有界负载模式下从键的位置顺时针跳过已满载的节点，节点容量为 ceil((1+ε)·平均负载)，
+// 按权重缩放；否则总是返回键的所有者
+func (m *Map) Acquire(key string) (node string, release func()) {
+	if key == "" {
+		return "", func() {}
+	}
+
+	r := m.ring.Load()
+	if len(r.keys) == 0 {
+		return "", func() {}
+	}
+	idx := r.search(hashing.String(m.config.hash(), key))
+
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+	if m.Bounded() {
+		node = m.boundedNode(r, idx)
+	} else {
+		node = r.owners[idx]
+	}
+	m.inflight[node]++
+	m.totalInflight++
+
+	var once sync.Once
+	return node, func() {
+		once.Do(func() { m.release(node) })
+	}
+}
+
+// release 将节点的进行中请求数减一
+func (m *Map) release(node string) {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+	if m.inflight[node] <= 1 {
+		delete(m.inflight, node)
+	} else {
+		m.inflight[node]--
+	}
+	m.totalInflight--
+}
+
+// Loads 返回每个节点进行中的请求数
+func (m *Map) Loads() map[string]int64 {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+	loads := make(map[string]int64, len(m.inflight))
+	for node, n := range m.inflight {
+		loads[node] = n
+	}
+	return loads
+}
+
+// boundedNode 从哈希环的 idx 位置顺时针找到第一个未满载的节点，调用前必须持有 loadMu
+func (m *Map) boundedNode(r *ring, idx int) string {
+	totalWeight := 0
+	for _, w := range r.weights {
+		totalWeight += w
+	}
+	// 平均负载计入即将分配的这个请求，保证总容量大于总负载，一定能找到未满载的节点
+	avg := float64(m.totalInflight+1) / float64(totalWeight)
+	for i := 0; i < len(r.keys); i++ {
+		node := r.owners[(idx+i)%len(r.keys)]
+		capacity := int64(math.Ceil((1 + m.config.LoadFactor) * avg * float64(r.weights[node])))
+		if m.inflight[node] < capacity {
+			return node
+		}
+	}
+	return r.owners[idx]
+}
@@ -0,0 +1,73 @@
+package consistentHash
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestAcquireBoundedLoad(t *testing.T) {
+	cfg := *DefaultConfig
+	cfg.LoadFactor = 0.25
+	m := New(WithConfig(&cfg))
+	m.Add("a", "b", "c", "d")
+
+	// 同一个热点键的所有请求不会全部落在所有者上
+	var releases []func()
+	for i := 0; i < 400; i++ {
+		_, release := m.Acquire("hot")
+		releases = append(releases, release)
+	}
+	capacity := int64(math.Ceil(1.25 * 400 / 4))
+	for node, n := range m.Loads() {
+		if n > capacity {
+			t.Errorf("node %s load %d exceeds capacity %d", node, n, capacity)
+		}
+	}
+
+	for _, release := range releases {
+		release()
+		release() // 重复调用不影响计数
+	}
+	if loads := m.Loads(); len(loads) != 0 {
+		t.Errorf("expected no in-flight requests, got %v", loads)
+	}
+
+	// 空闲时分配结果与所有者一致，不打乱哈希环
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		node, release := m.Acquire(key)
+		if owner := m.GetN(key, 1)[0]; node != owner {
+			t.Errorf("key %s: expected owner %s, got %s", key, owner, node)
+		}
+		release()
+	}
+}
+
+// 测试有界负载模式下 Get 总是返回所有者，不受也不影响进行中的请求数
+func TestGetIgnoresBoundedLoad(t *testing.T) {
+	cfg := *DefaultConfig
+	cfg.LoadFactor = 0.25
+	m := New(WithConfig(&cfg))
+	m.Add("a", "b", "c", "d")
+
+	owner := m.GetN("hot", 1)[0]
+	var overflowed bool
+	for i := 0; i < 100; i++ {
+		if node, _ := m.Acquire("hot"); node != owner {
+			overflowed = true
+		}
+	}
+	if !overflowed {
+		t.Fatal("expected Acquire to skip the full owner")
+	}
+	loads := m.Loads()
+	for i := 0; i < 100; i++ {
+		if node := m.Get("hot"); node != owner {
+			t.Fatalf("Get returned %s under load, want owner %s", node, owner)
+		}
+	}
+	if got := m.Loads(); fmt.Sprint(got) != fmt.Sprint(loads) {
+		t.Errorf("Get changed in-flight loads from %v to %v", loads, got)
+	}
+}
@@ -4,39 +4,62 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"sort"
+	"slices"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/wsss777/LRUCache/hashing"
 	"github.com/wsss777/LRUCache/logger"
 	"go.uber.org/zap"
 )
 
-// Map 一致性哈希实现
+// Map 一致性哈希实现。哈希环是不可变的快照，节点变化时整体替换，
+// 查找无锁且没有副作用，相同的节点和权重在任何节点上都得到相同的环
 type Map struct {
-	mu            sync.RWMutex
+	mu            sync.Mutex // 串行化节点变化
 	config        *Config
-	keys          []int            // 哈希环
-	hashMap       map[int]string   // 节点到节点的映射
-	nodeReplicas  map[string]int   // 节点到虚拟节点数量的映射
-	nodeCounts    map[string]int64 // 节点负载统计
-	totalRequests int64            // 总请求数
+	ring          atomic.Pointer[ring] // 当前哈希环快照
+	totalRequests int64                // 总请求数
+
+	rebalanceInterval time.Duration // 自适应调整虚拟节点的间隔，0表示不启用
+	stopOnce          sync.Once
+	stopCh            chan struct{}
+
+	vnodes *vnodeCache // 虚拟节点哈希缓存，只在持有 mu 时访问
+
+	loadMu        sync.Mutex       // 保护有界负载模式的进行中请求计数
+	inflight      map[string]int64 // 节点进行中的请求数
+	totalInflight int64            // 进行中的请求总数
+}
+
+// ring 哈希环快照，创建后不再修改
+type ring struct {
+	keys     []uint64          // 排好序的虚拟节点哈希
+	owners   []string          // 与 keys 一一对应的真实节点
+	replicas map[string]int    // 节点到虚拟节点数量的映射
+	weights  map[string]int    // 节点权重，虚拟节点数按权重缩放
+	counts   map[string]*int64 // 节点负载统计，计数器在快照之间共享
 }
 
 // New 创建一致性哈希实例
 func New(opts ...Option) *Map {
 	m := &Map{
-		config:       DefaultConfig,
-		hashMap:      make(map[int]string),
-		nodeReplicas: make(map[string]int),
-		nodeCounts:   make(map[string]int64),
+		config:   DefaultConfig,
+		stopCh:   make(chan struct{}),
+		vnodes:   newVnodeCache(),
+		inflight: make(map[string]int64),
 	}
 
 	for _, opt := range opts {
 		opt(m)
 	}
-	m.startBalancer()
+	m.ring.Store(m.buildRing(map[string]int{}, map[string]int{}, nil))
+	// 有界负载模式通过跳过满载节点均衡负载，不再调整虚拟节点
+	if m.rebalanceInterval > 0 && !m.Bounded() {
+		m.startBalancer()
+	}
 	return m
 }
 
@@ -49,6 +72,15 @@ func WithConfig(c *Config) Option {
 	}
 }
 
+// WithRebalancer 启用自适应负载均衡，每隔 interval 根据各节点的请求数调整虚拟节点数量。
+// 调整只依据本节点观察到的请求，各节点的环会因此不同，对同一个键的所有者产生分歧，
+// 只适用于单个客户端使用的场景。使用 Stop 停止
+func WithRebalancer(interval time.Duration) Option {
+	return func(m *Map) {
+		m.rebalanceInterval = interval
+	}
+}
+
 // Add 添加节点
 func (m *Map) Add(nodes ...string) error {
 	if len(nodes) == 0 {
@@ -56,18 +88,43 @@ func (m *Map) Add(nodes ...string) error {
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	r := m.ring.Load()
+	replicas, weights := r.copyNodes()
 	for _, node := range nodes {
 		if node == "" {
 			continue
 		}
-
-		m.addNode(node, m.config.DefaultReplicas)
+		replicas[node] = m.config.DefaultReplicas
+		weights[node] = 1
 	}
+	m.ring.Store(m.buildRing(replicas, weights, r.counts))
+	return nil
+}
 
-	sort.Ints(m.keys)
+// AddWeighted 按权重添加节点，虚拟节点数为 DefaultReplicas*weight，
+// 用于容量不同的机器混合部署。节点已存在时按新权重重新添加
+func (m *Map) AddWeighted(node string, weight int) error {
+	if node == "" {
+		return errors.New("addWeighted: node is empty")
+	}
+	if weight <= 0 {
+		return fmt.Errorf("addWeighted: invalid weight %d", weight)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.ring.Load()
+	replicas, weights := r.copyNodes()
+	replicas[node] = m.config.DefaultReplicas * weight
+	weights[node] = weight
+	m.ring.Store(m.buildRing(replicas, weights, r.counts))
 	return nil
 }
 
+// Weight 返回节点的权重，节点不存在时返回0
+func (m *Map) Weight(node string) int {
+	return m.ring.Load().weights[node]
+}
+
 // Remove 移除节点
 func (m *Map) Remove(node string) error {
 	if node == "" {
@@ -75,86 +132,198 @@ func (m *Map) Remove(node string) error {
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	replicas := m.nodeReplicas[node]
-	if replicas == 0 {
+	r := m.ring.Load()
+	if _, exists := r.replicas[node]; !exists {
 		logger.L().Error("remove: node not found",
 			zap.String("node", node))
+		return nil
 	}
-
-	for i := 0; i < replicas; i++ {
-		hash := int(m.config.HashFunc([]byte(fmt.Sprintf("%s-%d", node, i))))
-		delete(m.hashMap, hash)
-		// for j := 0; j < len(m.keys); j++ {
-		// 	if m.keys[j] == hash {
-		// 		m.keys = append(m.keys[:j], m.keys[j+1:]...)
-		// 		break
-		// 	}
-		// }
-		// 用二分查找定位 hash 在 keys 中的索引
-		idx := sort.Search(len(m.keys), func(j int) bool {
-			return m.keys[j] >= hash
-		})
-
-		// 确认找到了
-		if idx < len(m.keys) && m.keys[idx] == hash {
-			m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
-		}
-	}
-	delete(m.nodeReplicas, node)
-	delete(m.nodeCounts, node)
+	replicas, weights := r.copyNodes()
+	delete(replicas, node)
+	delete(weights, node)
+	m.vnodes.forget(node)
+	m.ring.Store(m.buildRing(replicas, weights, r.counts))
 	return nil
-
 }
 
-// Get 获取节点
+// Get 获取键的所有者。有界负载模式下同样返回所有者，不检查也不占用节点容量，
+// 需要按负载分配节点时使用 Acquire
 func (m *Map) Get(key string) string {
 	if key == "" {
 		return ""
 	}
 
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if len(m.keys) == 0 {
+	r := m.ring.Load()
+	if len(r.keys) == 0 {
 		return ""
 	}
-	hash := int(m.config.HashFunc([]byte(key)))
-	idx := sort.Search(len(m.keys), func(j int) bool {
-		return m.keys[j] >= hash
-	})
+	idx := r.search(hashing.String(m.config.hash(), key))
 
-	if idx == len(m.keys) {
-		idx = 0
-	}
-
-	node := m.hashMap[m.keys[idx]]
-	count := m.nodeCounts[node]
-	m.nodeCounts[node] = count + 1
+	node := r.owners[idx]
+	atomic.AddInt64(r.counts[node], 1)
 	atomic.AddInt64(&m.totalRequests, 1)
 	return node
 }
 
-// addNode 添加节点的虚拟节点
-func (m *Map) addNode(node string, replicas int) {
-	for i := 0; i < replicas; i++ {
-		hash := int(m.config.HashFunc([]byte(fmt.Sprintf("%s-%d", node, i))))
-		m.keys = append(m.keys, hash)
-		m.hashMap[hash] = node
+// GetSuccessor 获取键的后继节点，即从键的所有者沿哈希环顺时针找到的第一个不同的真实节点，
+// 用于所有者不可用时指定唯一的接替节点。只有一个节点时返回空字符串
+func (m *Map) GetSuccessor(key string) string {
+	return Successor(m, key)
+}
+
+// GetN 从键在哈希环上的位置顺时针查找 n 个不同的真实节点，第一个为键的所有者，
+// 用于多副本放置和故障转移。真实节点不足 n 个时返回所有节点
+func (m *Map) GetN(key string, n int) []string {
+	if key == "" || n <= 0 {
+		return nil
+	}
+
+	r := m.ring.Load()
+	if len(r.keys) == 0 {
+		return nil
+	}
+	if n > len(r.replicas) {
+		n = len(r.replicas)
+	}
+	idx := r.search(hashing.String(m.config.hash(), key))
+
+	nodes := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for i := 0; i < len(r.keys) && len(nodes) < n; i++ {
+		node := r.owners[(idx+i)%len(r.keys)]
+		if _, ok := seen[node]; ok {
+			continue
+		}
+		seen[node] = struct{}{}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// buildRing 根据节点的虚拟节点数创建哈希环快照，调用前必须持有 mu
+func (m *Map) buildRing(replicas, weights map[string]int, counts map[string]*int64) *ring {
+	return newRing(m.config, replicas, weights, counts, m.vnodes)
+}
+
+// newRing 创建哈希环快照，虚拟节点哈希冲突时保留名称较小的节点，使结果与添加顺序无关。
+// vnodes 为 nil 时不使用缓存
+func newRing(config *Config, replicas, weights map[string]int, counts map[string]*int64, vnodes *vnodeCache) *ring {
+	r := &ring{
+		replicas: replicas,
+		weights:  weights,
+		counts:   make(map[string]*int64, len(replicas)),
+	}
+	owners := make(map[uint64]string)
+	for node, n := range replicas {
+		if c, ok := counts[node]; ok {
+			r.counts[node] = c
+		} else {
+			r.counts[node] = new(int64)
+		}
+		for _, hash := range vnodes.get(config.hash(), node, n) {
+			if owner, ok := owners[hash]; !ok || node < owner {
+				owners[hash] = node
+			}
+		}
+	}
+	r.keys = make([]uint64, 0, len(owners))
+	for hash := range owners {
+		r.keys = append(r.keys, hash)
+	}
+	slices.Sort(r.keys)
+	r.owners = make([]string, len(r.keys))
+	for i, hash := range r.keys {
+		r.owners[i] = owners[hash]
+	}
+	return r
+}
+
+// vnodeCache 缓存每个节点虚拟节点名称（"节点-序号"）的哈希。节点变化时其余节点的哈希不变，
+// 重建哈希环时直接复用，不必重新格式化名称和计算哈希
+type vnodeCache struct {
+	hashes map[string][]uint64
+}
+
+// newVnodeCache 创建虚拟节点哈希缓存
+func newVnodeCache() *vnodeCache {
+	return &vnodeCache{hashes: make(map[string][]uint64)}
+}
+
+// get 返回节点前 n 个虚拟节点的哈希，缓存不足时补算
+func (c *vnodeCache) get(hash hashing.Func, node string, n int) []uint64 {
+	if c == nil {
+		return appendVnodeHashes(hash, node, nil, n)
+	}
+	hashes := c.hashes[node]
+	if len(hashes) < n {
+		hashes = appendVnodeHashes(hash, node, hashes, n)
+		c.hashes[node] = hashes
+	}
+	return hashes[:n]
+}
+
+// forget 删除节点的缓存
+func (c *vnodeCache) forget(node string) {
+	delete(c.hashes, node)
+}
+
+// appendVnodeHashes 计算节点第 len(hashes) 到 n-1 个虚拟节点的哈希并追加到 hashes
+func appendVnodeHashes(hash hashing.Func, node string, hashes []uint64, n int) []uint64 {
+	buf := make([]byte, 0, len(node)+8)
+	buf = append(buf, node...)
+	buf = append(buf, '-')
+	prefix := len(buf)
+	for i := len(hashes); i < n; i++ {
+		buf = strconv.AppendInt(buf[:prefix], int64(i), 10)
+		hashes = append(hashes, hash(buf))
 	}
-	m.nodeReplicas[node] = replicas
+	return hashes
+}
+
+// copyNodes 复制节点的虚拟节点数和权重，用于创建新快照
+func (r *ring) copyNodes() (replicas, weights map[string]int) {
+	replicas = make(map[string]int, len(r.replicas)+1)
+	weights = make(map[string]int, len(r.weights)+1)
+	for node, n := range r.replicas {
+		replicas[node] = n
+	}
+	for node, w := range r.weights {
+		weights[node] = w
+	}
+	return replicas, weights
+}
+
+// search 返回哈希在环上顺时针遇到的第一个虚拟节点的下标
+func (r *ring) search(hash uint64) int {
+	idx, _ := slices.BinarySearch(r.keys, hash)
+	if idx == len(r.keys) {
+		idx = 0
+	}
+	return idx
+}
+
+// expectedLoad 按权重计算节点应承担的请求数
+func (r *ring) expectedLoad(node string, total int64) float64 {
+	totalWeight := 0
+	for _, w := range r.weights {
+		totalWeight += w
+	}
+	return float64(total) * float64(r.weights[node]) / float64(totalWeight)
 }
 
 // checkAndRebalance 检查并重新平衡虚拟节点
 func (m *Map) checkAndRebalance() {
-	if atomic.LoadInt64(&m.totalRequests) < 1000 {
+	total := atomic.LoadInt64(&m.totalRequests)
+	if total < 1000 {
 		return
 	}
-	avgLoad := float64(m.totalRequests) / float64(len(m.nodeReplicas))
+	r := m.ring.Load()
 	var maxDiff float64
-	for _, count := range m.nodeCounts {
-		diff := math.Abs(float64(count) - avgLoad)
-		if diff/avgLoad > maxDiff {
-			maxDiff = diff / avgLoad
+	for node, count := range r.counts {
+		expected := r.expectedLoad(node, total)
+		diff := math.Abs(float64(atomic.LoadInt64(count)) - expected)
+		if diff/expected > maxDiff {
+			maxDiff = diff / expected
 		}
 	}
 	// 如果负载不均衡度超过阈值，调整虚拟节点
@@ -163,16 +332,17 @@ func (m *Map) checkAndRebalance() {
 	}
 }
 
-// rebalanceNodes 重新平衡节点
+// rebalanceNodes 重新平衡节点，虚拟节点数的上下限按节点权重缩放
 func (m *Map) rebalanceNode() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	avgLoad := float64(m.totalRequests) / float64(len(m.nodeReplicas))
+	r := m.ring.Load()
+	total := atomic.LoadInt64(&m.totalRequests)
+	replicas, weights := r.copyNodes()
 	// 调整每个节点的虚拟节点数量
-	for node, count := range m.nodeReplicas {
-		currentReplicas := m.nodeReplicas[node]
-		loadRatio := float64(count) / avgLoad
+	for node, currentReplicas := range r.replicas {
+		loadRatio := float64(atomic.LoadInt64(r.counts[node])) / r.expectedLoad(node, total)
 
 		var newReplicas int
 		if loadRatio > 1 {
@@ -180,50 +350,54 @@ func (m *Map) rebalanceNode() {
 		} else {
 			newReplicas = int(float64(currentReplicas) * (2 - loadRatio))
 		}
-		if newReplicas < m.config.MinReplicas {
-			newReplicas = m.config.MinReplicas
-		}
-		if newReplicas > m.config.MaxReplicas {
-			newReplicas = m.config.MaxReplicas
+		weight := weights[node]
+		if newReplicas < m.config.MinReplicas*weight {
+			newReplicas = m.config.MinReplicas * weight
 		}
-
-		if newReplicas != currentReplicas {
-			if err := m.Remove(node); err != nil {
-				continue
-			}
-			m.addNode(node, newReplicas)
+		if newReplicas > m.config.MaxReplicas*weight {
+			newReplicas = m.config.MaxReplicas * weight
 		}
+		replicas[node] = newReplicas
 	}
-	for node := range m.nodeCounts {
-		m.nodeCounts[node] = 0
-	}
+	// 计数器随新快照一起重置
+	m.ring.Store(m.buildRing(replicas, weights, nil))
 	atomic.StoreInt64(&m.totalRequests, 0)
-	sort.Ints(m.keys)
 }
 
-// GetStats 获取负载统计信息
+// GetStats 获取负载统计信息，即每个节点处理的请求占比
 func (m *Map) GetStats() map[string]float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	r := m.ring.Load()
 	stats := make(map[string]float64)
 	total := atomic.LoadInt64(&m.totalRequests)
 	if total == 0 {
 		return stats
 	}
-	for node, count := range m.nodeReplicas {
-		stats[node] = float64(count) / float64(total)
+	for node, count := range r.counts {
+		stats[node] = float64(atomic.LoadInt64(count)) / float64(total)
 	}
 	return stats
 }
 
+// Stop 停止自适应负载均衡，可以重复调用
+func (m *Map) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+}
+
 // 将checkAndRebalance移到单独的goroutine中
 func (m *Map) startBalancer() {
 	go func() {
-		ticker := time.NewTicker(time.Second)
+		ticker := time.NewTicker(m.rebalanceInterval)
 		defer ticker.Stop()
 
-		for range ticker.C {
-			m.checkAndRebalance()
+		for {
+			select {
+			case <-ticker.C:
+				m.checkAndRebalance()
+			case <-m.stopCh:
+				return
+			}
 		}
 	}()
 }
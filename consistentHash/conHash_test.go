@@ -0,0 +1,92 @@
+package consistentHash
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMapDeterministic(t *testing.T) {
+	m1 := New()
+	m1.Add("a", "b", "c")
+	m1.AddWeighted("d", 2)
+
+	m2 := New()
+	m2.AddWeighted("d", 2)
+	m2.Add("c")
+	m2.Add("b", "a", "e")
+	m2.Remove("e")
+
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if n1, n2 := m1.Get(key), m2.Get(key); n1 != n2 {
+			t.Fatalf("key %s: %s != %s", key, n1, n2)
+		}
+	}
+}
+
+func TestMapConcurrentGet(t *testing.T) {
+	m := New()
+	m.Add("a", "b")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if node := m.Get(fmt.Sprintf("key-%d-%d", i, j)); node == "" {
+					t.Error("empty node")
+					return
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		m.Add(fmt.Sprintf("node-%d", i))
+		m.Remove(fmt.Sprintf("node-%d", i))
+	}
+	wg.Wait()
+}
+
+func TestMapCachedVnodesMatchFresh(t *testing.T) {
+	// 权重先增后减，缓存中多出的虚拟节点哈希不应影响结果
+	m := New()
+	m.Add("a", "b", "c")
+	m.AddWeighted("a", 3)
+	m.AddWeighted("a", 1)
+
+	fresh := newRing(m.config, map[string]int{"a": 50, "b": 50, "c": 50},
+		map[string]int{"a": 1, "b": 1, "c": 1}, nil, nil)
+	r := m.ring.Load()
+	if len(r.keys) != len(fresh.keys) {
+		t.Fatalf("ring has %d points, fresh ring has %d", len(r.keys), len(fresh.keys))
+	}
+	for i := range r.keys {
+		if r.keys[i] != fresh.keys[i] || r.owners[i] != fresh.owners[i] {
+			t.Fatalf("point %d differs: %d/%s vs %d/%s", i, r.keys[i], r.owners[i], fresh.keys[i], fresh.owners[i])
+		}
+	}
+}
+
+func TestMapDistribution(t *testing.T) {
+	m := New()
+	nodes := []string{"10.0.0.1:8001", "10.0.0.2:8001", "10.0.0.3:8001", "10.0.0.4:8001"}
+	m.Add(nodes...)
+	if n := len(m.ring.Load().keys); n != len(nodes)*DefaultConfig.DefaultReplicas {
+		t.Errorf("expected no virtual node collisions in 64-bit space, got %d points", n)
+	}
+
+	const keys = 100000
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		counts[m.Get(fmt.Sprintf("key-%d", i))]++
+	}
+	// 50 个虚拟节点时各节点份额的标准差约为 14%，留出足够余量
+	expected := float64(keys) / float64(len(nodes))
+	for _, node := range nodes {
+		if ratio := float64(counts[node]) / expected; ratio < 0.6 || ratio > 1.4 {
+			t.Errorf("node %s got %.2f of its expected share", node, ratio)
+		}
+	}
+}
@@ -1,13 +1,15 @@
 package consistentHash
 
-import "hash/crc32"
+import "github.com/wsss777/LRUCache/hashing"
 
 type Config struct { // Config 一致性哈希配置
 	DefaultReplicas      int                      // 每个真实节点对应的虚拟节点数
 	MinReplicas          int                      // 最小虚拟节点数
 	MaxReplicas          int                      // 最大虚拟节点数
-	HashFunc             func(data []byte) uint32 // 哈希函数
+	HashFunc             func(data []byte) uint32 // 32 位哈希函数，为兼容保留，结果经混合后分散到 64 位空间，Hash64 非空时忽略
+	Hash64               hashing.Func             // 64 位哈希函数，HashFunc 和 Hash64 都为空时使用 hashing.Default
 	LoadBalanceThreshold float64                  // 负载均衡阈值，超过此值触发虚拟节点调整
+	LoadFactor           float64                  // 有界负载模式的 ε，大于0时启用，每个节点最多承担 ceil((1+ε)·平均负载) 个进行中的请求，且不再调整虚拟节点
 }
 
 // DefaultConfig 默认配置
@@ -15,6 +17,17 @@ var DefaultConfig = &Config{
 	DefaultReplicas:      50,
 	MinReplicas:          10,
 	MaxReplicas:          200,
-	HashFunc:             crc32.ChecksumIEEE,
 	LoadBalanceThreshold: 0.25, // 25% 的负载不均衡度触发调整
 }
+
+// hash 返回哈希环使用的 64 位哈希函数
+func (c *Config) hash() hashing.Func {
+	switch {
+	case c.Hash64 != nil:
+		return c.Hash64
+	case c.HashFunc != nil:
+		return hashing.FromUint32(c.HashFunc)
+	default:
+		return hashing.Default
+	}
+}
@@ -0,0 +1,197 @@
+package consistentHash
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/wsss777/LRUCache/hashing"
+)
+
+// DefaultMaglevTableSize Maglev 查找表的默认大小，必须为质数
+const DefaultMaglevTableSize = 65537
+
+// Maglev Google Maglev 一致性哈希：每个节点按各自的排列轮流填充固定大小的查找表，
+// 查找为 O(1)，负载几乎完全均衡，节点增减时只有少量额外的键移动
+type Maglev struct {
+	mu      sync.RWMutex
+	size    uint64         // 查找表大小
+	nodes   []string       // 按名称排序的节点
+	weights map[string]int // 节点权重
+	table   []int          // 查找表，保存节点在 nodes 中的下标
+}
+
+// NewMaglev 创建 Maglev 节点选择器，tableSize 应远大于节点数，<=1 时使用默认大小。
+// 查找表大小必须为质数，否则节点的排列无法覆盖所有槽位，不是质数时向上取最近的质数
+func NewMaglev(tableSize int) *Maglev {
+	if tableSize <= 1 {
+		tableSize = DefaultMaglevTableSize
+	}
+	return &Maglev{
+		size:    nextPrime(uint64(tableSize)),
+		weights: make(map[string]int),
+	}
+}
+
+// nextPrime 返回大于或等于 n 的最小质数
+func nextPrime(n uint64) uint64 {
+	for ; ; n++ {
+		if isPrime(n) {
+			return n
+		}
+	}
+}
+
+// isPrime 试除法判断质数，查找表大小有限，开销可以忽略
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for d := uint64(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add 添加节点，权重为1
+func (m *Maglev) Add(nodes ...string) error {
+	if len(nodes) == 0 {
+		return errors.New("add:  nodes is empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, node := range nodes {
+		if node == "" {
+			continue
+		}
+		m.addNode(node, 1)
+	}
+	m.populate()
+	return nil
+}
+
+// AddWeighted 按权重添加节点，节点占据的查找表槽位数与权重成正比
+func (m *Maglev) AddWeighted(node string, weight int) error {
+	if node == "" {
+		return errors.New("addWeighted: node is empty")
+	}
+	if weight <= 0 {
+		return fmt.Errorf("addWeighted: invalid weight %d", weight)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addNode(node, weight)
+	m.populate()
+	return nil
+}
+
+// addNode 添加或更新节点，调用前必须持有锁
+func (m *Maglev) addNode(node string, weight int) {
+	if _, exists := m.weights[node]; !exists {
+		idx := sort.SearchStrings(m.nodes, node)
+		m.nodes = append(m.nodes, "")
+		copy(m.nodes[idx+1:], m.nodes[idx:])
+		m.nodes[idx] = node
+	}
+	m.weights[node] = weight
+}
+
+// Remove 移除节点
+func (m *Maglev) Remove(node string) error {
+	if node == "" {
+		return errors.New("remove : node is empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.weights[node]; !exists {
+		return nil
+	}
+	idx := sort.SearchStrings(m.nodes, node)
+	m.nodes = append(m.nodes[:idx], m.nodes[idx+1:]...)
+	delete(m.weights, node)
+	m.populate()
+	return nil
+}
+
+// Get 查表获取节点
+func (m *Maglev) Get(key string) string {
+	if key == "" {
+		return ""
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.table) == 0 {
+		return ""
+	}
+	return m.nodes[m.table[hashing.XXHashString(key)%m.size]]
+}
+
+// GetN 从键在查找表中的位置向后查找 n 个不同的节点，第一个与 Get 的结果相同
+func (m *Maglev) GetN(key string, n int) []string {
+	if key == "" || n <= 0 {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.table) == 0 {
+		return nil
+	}
+	if n > len(m.nodes) {
+		n = len(m.nodes)
+	}
+	start := hashing.XXHashString(key) % m.size
+	nodes := make([]string, 0, n)
+	seen := make(map[int]struct{}, n)
+	for i := uint64(0); i < m.size && len(nodes) < n; i++ {
+		idx := m.table[(start+i)%m.size]
+		if _, ok := seen[idx]; ok {
+			continue
+		}
+		seen[idx] = struct{}{}
+		nodes = append(nodes, m.nodes[idx])
+	}
+	return nodes
+}
+
+// populate 重建查找表：每轮每个节点按权重依次取自己排列中下一个空槽位，直到填满，调用前必须持有锁
+func (m *Maglev) populate() {
+	if len(m.nodes) == 0 {
+		m.table = nil
+		return
+	}
+	offsets := make([]uint64, len(m.nodes))
+	skips := make([]uint64, len(m.nodes))
+	next := make([]uint64, len(m.nodes))
+	for i, node := range m.nodes {
+		h := hashing.XXHashString(node)
+		offsets[i] = h % m.size
+		skips[i] = hashing.Mix64(h)%(m.size-1) + 1
+	}
+
+	table := make([]int, m.size)
+	for i := range table {
+		table[i] = -1
+	}
+	var filled uint64
+	for {
+		for i, node := range m.nodes {
+			for t := 0; t < m.weights[node]; t++ {
+				c := (offsets[i] + next[i]*skips[i]) % m.size
+				for table[c] >= 0 {
+					next[i]++
+					c = (offsets[i] + next[i]*skips[i]) % m.size
+				}
+				table[c] = i
+				next[i]++
+				filled++
+				if filled == m.size {
+					m.table = table
+					return
+				}
+			}
+		}
+	}
+}
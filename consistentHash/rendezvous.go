@@ -0,0 +1,138 @@
+package consistentHash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/wsss777/LRUCache/hashing"
+)
+
+// Rendezvous 最高随机权重（HRW）哈希：每个键对所有节点打分，得分最高的节点负责该键。
+// 节点增减时只有归属于该节点的键会移动，不需要虚拟节点，查找复杂度为 O(节点数)
+type Rendezvous struct {
+	mu      sync.RWMutex
+	nodes   []string          // 按名称排序的节点
+	hashes  map[string]uint64 // 节点名称的哈希
+	weights map[string]int    // 节点权重
+}
+
+// NewRendezvous 创建 HRW 节点选择器
+func NewRendezvous() *Rendezvous {
+	return &Rendezvous{
+		hashes:  make(map[string]uint64),
+		weights: make(map[string]int),
+	}
+}
+
+// Add 添加节点，权重为1
+func (r *Rendezvous) Add(nodes ...string) error {
+	if len(nodes) == 0 {
+		return errors.New("add:  nodes is empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, node := range nodes {
+		if node == "" {
+			continue
+		}
+		r.addNode(node, 1)
+	}
+	return nil
+}
+
+// AddWeighted 按权重添加节点，节点得到的键数与权重成正比
+func (r *Rendezvous) AddWeighted(node string, weight int) error {
+	if node == "" {
+		return errors.New("addWeighted: node is empty")
+	}
+	if weight <= 0 {
+		return fmt.Errorf("addWeighted: invalid weight %d", weight)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addNode(node, weight)
+	return nil
+}
+
+// addNode 添加或更新节点，调用前必须持有锁
+func (r *Rendezvous) addNode(node string, weight int) {
+	if _, exists := r.weights[node]; !exists {
+		idx := sort.SearchStrings(r.nodes, node)
+		r.nodes = append(r.nodes, "")
+		copy(r.nodes[idx+1:], r.nodes[idx:])
+		r.nodes[idx] = node
+		r.hashes[node] = hashing.XXHashString(node)
+	}
+	r.weights[node] = weight
+}
+
+// Remove 移除节点
+func (r *Rendezvous) Remove(node string) error {
+	if node == "" {
+		return errors.New("remove : node is empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.weights[node]; !exists {
+		return nil
+	}
+	idx := sort.SearchStrings(r.nodes, node)
+	r.nodes = append(r.nodes[:idx], r.nodes[idx+1:]...)
+	delete(r.hashes, node)
+	delete(r.weights, node)
+	return nil
+}
+
+// Get 获取得分最高的节点
+func (r *Rendezvous) Get(key string) string {
+	if key == "" {
+		return ""
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keyHash := hashing.XXHashString(key)
+	var best string
+	bestScore := math.Inf(-1)
+	for _, node := range r.nodes {
+		if score := r.score(keyHash, node); score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// GetN 按得分从高到低返回 n 个节点，第一个与 Get 的结果相同
+func (r *Rendezvous) GetN(key string, n int) []string {
+	if key == "" || n <= 0 {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keyHash := hashing.XXHashString(key)
+	scores := make(map[string]float64, len(r.nodes))
+	nodes := make([]string, len(r.nodes))
+	copy(nodes, r.nodes)
+	for _, node := range nodes {
+		scores[node] = r.score(keyHash, node)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return scores[nodes[i]] > scores[nodes[j]]
+	})
+	if len(nodes) > n {
+		nodes = nodes[:n]
+	}
+	return nodes
+}
+
+// score 计算节点对键的加权得分 -w/ln(u)，u 为 (0,1) 上的均匀分布，调用前必须持有锁
+func (r *Rendezvous) score(keyHash uint64, node string) float64 {
+	h := hashing.Mix64(keyHash ^ r.hashes[node])
+	// 取高 53 位转换为 (0,1) 开区间上的浮点数
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -float64(r.weights[node]) / math.Log(u)
+}
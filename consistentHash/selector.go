@@ -0,0 +1,38 @@
+package consistentHash
+
+// NodeSelector 节点选择策略，根据键选出负责的真实节点
+type NodeSelector interface {
+	Add(nodes ...string) error
+	Remove(node string) error
+	Get(key string) string
+	GetN(key string, n int) []string
+}
+
+// WeightedSelector 可选接口，支持按权重添加节点，节点已存在时按新权重重新添加
+type WeightedSelector interface {
+	AddWeighted(node string, weight int) error
+}
+
+// BoundedSelector 可选接口，支持有界负载分配
+type BoundedSelector interface {
+	Bounded() bool
+	Acquire(key string) (node string, release func())
+	Loads() map[string]int64
+}
+
+var _ NodeSelector = (*Map)(nil)
+var _ WeightedSelector = (*Map)(nil)
+var _ BoundedSelector = (*Map)(nil)
+var _ NodeSelector = (*Rendezvous)(nil)
+var _ WeightedSelector = (*Rendezvous)(nil)
+var _ NodeSelector = (*Maglev)(nil)
+var _ WeightedSelector = (*Maglev)(nil)
+
+// Successor 返回键的后继节点，即 GetN 结果中的第二个节点，节点不足两个时返回空字符串
+func Successor(s NodeSelector, key string) string {
+	nodes := s.GetN(key, 2)
+	if len(nodes) < 2 {
+		return ""
+	}
+	return nodes[1]
+}
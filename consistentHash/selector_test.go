@@ -0,0 +1,114 @@
+package consistentHash
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNodeSelectors(t *testing.T) {
+	selectors := map[string]func() NodeSelector{
+		"ring":       func() NodeSelector { return New() },
+		"rendezvous": func() NodeSelector { return NewRendezvous() },
+		"maglev":     func() NodeSelector { return NewMaglev(0) },
+	}
+	nodes := []string{"a", "b", "c", "d", "e"}
+	const keys = 20000
+
+	for name, newSelector := range selectors {
+		t.Run(name, func(t *testing.T) {
+			s := newSelector()
+			if err := s.Add(nodes...); err != nil {
+				t.Fatal(err)
+			}
+
+			before := make(map[string]string, keys)
+			counts := make(map[string]int)
+			for i := 0; i < keys; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				owner := s.Get(key)
+				replicas := s.GetN(key, 3)
+				if len(replicas) != 3 || replicas[0] != owner {
+					t.Fatalf("key %s: GetN=%v, Get=%s", key, replicas, owner)
+				}
+				if replicas[0] == replicas[1] || replicas[1] == replicas[2] || replicas[0] == replicas[2] {
+					t.Fatalf("key %s: duplicate replicas %v", key, replicas)
+				}
+				before[key] = owner
+				counts[owner]++
+			}
+			for _, node := range nodes {
+				if share := float64(counts[node]) / keys; share < 0.1 || share > 0.3 {
+					t.Errorf("node %s owns %.2f of keys", node, share)
+				}
+			}
+
+			// 移除节点后，原本不属于该节点的键绝大部分保持不动
+			if err := s.Remove("c"); err != nil {
+				t.Fatal(err)
+			}
+			moved := 0
+			for key, owner := range before {
+				now := s.Get(key)
+				if now == "c" {
+					t.Fatalf("key %s still assigned to removed node", key)
+				}
+				if owner != "c" && now != owner {
+					moved++
+				}
+			}
+			if frac := float64(moved) / keys; frac > 0.05 {
+				t.Errorf("%.3f of keys moved between surviving nodes", frac)
+			}
+		})
+	}
+}
+
+func TestWeightedSelectors(t *testing.T) {
+	selectors := map[string]WeightedSelector{
+		"ring":       New(),
+		"rendezvous": NewRendezvous(),
+		"maglev":     NewMaglev(0),
+	}
+	for name, s := range selectors {
+		t.Run(name, func(t *testing.T) {
+			s.AddWeighted("small", 1)
+			s.AddWeighted("large", 3)
+			counts := make(map[string]int)
+			for i := 0; i < 20000; i++ {
+				counts[s.(NodeSelector).Get(fmt.Sprintf("key-%d", i))]++
+			}
+			if ratio := float64(counts["large"]) / float64(counts["small"]); ratio < 2 || ratio > 4 {
+				t.Errorf("large/small = %.2f, want about 3", ratio)
+			}
+		})
+	}
+}
+
+func TestMaglevCompositeTableSize(t *testing.T) {
+	// 合数大小下节点的排列无法覆盖所有槽位，填表会死循环，应向上取质数
+	m := NewMaglev(1000)
+	if m.size != 1009 {
+		t.Fatalf("table size = %d, want 1009", m.size)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Add("a", "b", "c", "d", "e", "f", "g", "h")
+		m.AddWeighted("i", 4)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("populate did not finish")
+	}
+
+	counts := make(map[string]int)
+	for _, idx := range m.table {
+		counts[m.nodes[idx]]++
+	}
+	if len(counts) != 9 {
+		t.Errorf("table covers %d nodes, want 9", len(counts))
+	}
+}
@@ -0,0 +1,161 @@
+package consistentHash
+
+import (
+	"math"
+	"sort"
+
+	"github.com/wsss777/LRUCache/hashing"
+)
+
+// hashSpace 哈希空间大小，哈希函数的结果覆盖整个 64 位空间
+const hashSpace = float64(1 << 64)
+
+// Snapshot 哈希环某一时刻的只读快照，用于对比节点变化前后的归属
+type Snapshot struct {
+	config *Config
+	ring   *ring
+}
+
+// Range 哈希空间上的一段闭区间 [Start, Last]，64 位空间的终点无法用开区间表示
+type Range struct {
+	Start uint64
+	Last  uint64
+}
+
+// Contains 判断哈希值是否落在区间内
+func (r Range) Contains(hash uint64) bool {
+	return hash >= r.Start && hash <= r.Last
+}
+
+// share 区间占整个哈希空间的比例
+func (r Range) share() float64 {
+	return (float64(r.Last-r.Start) + 1) / hashSpace
+}
+
+// MovedRange 归属发生变化的哈希区间，From 为空表示之前没有节点，To 为空表示之后没有节点
+type MovedRange struct {
+	Range
+	From string
+	To   string
+}
+
+// Snapshot 返回当前哈希环的快照
+func (m *Map) Snapshot() *Snapshot {
+	return &Snapshot{config: m.config, ring: m.ring.Load()}
+}
+
+// Nodes 返回快照中的所有节点，按名称排序
+func (s *Snapshot) Nodes() []string {
+	nodes := make([]string, 0, len(s.ring.replicas))
+	for node := range s.ring.replicas {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Get 返回快照中负责键的节点
+func (s *Snapshot) Get(key string) string {
+	return s.owner(s.Hash(key))
+}
+
+// Hash 计算键在哈希空间中的位置，可以与 Range.Contains 配合筛选需要迁移的键
+func (s *Snapshot) Hash(key string) uint64 {
+	return hashing.String(s.config.hash(), key)
+}
+
+// WithNode 返回按权重加入节点后的快照，不影响原哈希环，用于在变更前评估数据迁移量
+func (s *Snapshot) WithNode(node string, weight int) *Snapshot {
+	if weight <= 0 {
+		weight = 1
+	}
+	replicas, weights := s.ring.copyNodes()
+	replicas[node] = s.config.DefaultReplicas * weight
+	weights[node] = weight
+	return &Snapshot{config: s.config, ring: newRing(s.config, replicas, weights, nil, nil)}
+}
+
+// WithoutNode 返回移除节点后的快照，不影响原哈希环
+func (s *Snapshot) WithoutNode(node string) *Snapshot {
+	replicas, weights := s.ring.copyNodes()
+	delete(replicas, node)
+	delete(weights, node)
+	return &Snapshot{config: s.config, ring: newRing(s.config, replicas, weights, nil, nil)}
+}
+
+// owner 返回哈希值的归属节点
+func (s *Snapshot) owner(hash uint64) string {
+	if len(s.ring.keys) == 0 {
+		return ""
+	}
+	return s.ring.owners[s.ring.search(hash)]
+}
+
+// boundaries 返回快照中所有区间的起点：虚拟节点 k 负责 (前一个虚拟节点, k]，即从 k+1 开始的是下一个区间
+func (s *Snapshot) boundaries() []uint64 {
+	points := make([]uint64, 0, len(s.ring.keys))
+	for _, k := range s.ring.keys {
+		if k != math.MaxUint64 {
+			points = append(points, k+1)
+		}
+	}
+	return points
+}
+
+// Diff 对比两个快照，返回归属发生变化的哈希区间，按起点排序，相邻且变化相同的区间会合并。
+// 两个快照必须使用相同的哈希函数
+func Diff(before, after *Snapshot) []MovedRange {
+	points := append([]uint64{0}, before.boundaries()...)
+	points = append(points, after.boundaries()...)
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	var moved []MovedRange
+	for i, start := range points {
+		if i > 0 && start == points[i-1] {
+			continue
+		}
+		last := uint64(math.MaxUint64)
+		for j := i + 1; j < len(points); j++ {
+			if points[j] != start {
+				last = points[j] - 1
+				break
+			}
+		}
+		// 区间内任意一点在两个快照中的归属都相同，取起点判断
+		from, to := before.owner(start), after.owner(start)
+		if from == to {
+			continue
+		}
+		if n := len(moved); n > 0 && moved[n-1].Last+1 == start && moved[n-1].From == from && moved[n-1].To == to {
+			moved[n-1].Last = last
+			continue
+		}
+		moved = append(moved, MovedRange{Range: Range{Start: start, Last: last}, From: from, To: to})
+	}
+	return moved
+}
+
+// MovedFraction 估算移动的键占全部键的比例，假设键的哈希在哈希空间中均匀分布
+func MovedFraction(moved []MovedRange) float64 {
+	var total float64
+	for _, r := range moved {
+		total += r.share()
+	}
+	return total
+}
+
+// MovementByNode 按节点汇总移入和移出的哈希空间比例，用于评估每个节点的迁移量
+func MovementByNode(moved []MovedRange) (out, in map[string]float64) {
+	out = make(map[string]float64)
+	in = make(map[string]float64)
+	for _, r := range moved {
+		share := r.share()
+		if r.From != "" {
+			out[r.From] += share
+		}
+		if r.To != "" {
+			in[r.To] += share
+		}
+	}
+	return out, in
+}
@@ -0,0 +1,59 @@
+package consistentHash
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	m := New()
+	m.Add("a", "b", "c")
+	before := m.Snapshot()
+	m.Add("d")
+	after := m.Snapshot()
+
+	moved := Diff(before, after)
+	if len(moved) == 0 {
+		t.Fatal("expected moved ranges")
+	}
+	for _, r := range moved {
+		if r.To != "d" {
+			t.Fatalf("range %+v moved to %s, want d", r.Range, r.To)
+		}
+	}
+
+	// 逐个键验证：归属变化的键恰好落在移动区间内
+	const keys = 20000
+	changed := 0
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		hash := after.Hash(key)
+		inMoved := false
+		for _, r := range moved {
+			if r.Contains(hash) {
+				inMoved = true
+				break
+			}
+		}
+		if diff := before.Get(key) != after.Get(key); diff != inMoved {
+			t.Fatalf("key %s: owner changed=%v, in moved range=%v", key, diff, inMoved)
+		}
+		if inMoved {
+			changed++
+		}
+	}
+
+	estimated := MovedFraction(moved)
+	if actual := float64(changed) / keys; estimated-actual > 0.03 || actual-estimated > 0.03 {
+		t.Errorf("estimated %.3f, actual %.3f", estimated, actual)
+	}
+
+	// 规划移除节点不影响当前哈希环
+	planned := after.WithoutNode("d")
+	if frac := MovedFraction(Diff(before, planned)); frac != 0 {
+		t.Errorf("removing the added node should restore the ring, moved %.3f", frac)
+	}
+	if len(m.Snapshot().Nodes()) != 4 {
+		t.Error("planning changed the live ring")
+	}
+}
@@ -0,0 +1,61 @@
+package consistentHash
+
+import "math"
+
+// ZoneFunc 返回节点所在的可用区，未知时返回空字符串
+type ZoneFunc func(node string) string
+
+// GetNZones 按 s.GetN 的优先顺序为键选出 n 个节点，优先选择可用区互不相同的节点，
+// 使副本分散在不同可用区；可用区不足 n 个时再按原顺序补足。第一个节点始终是键的所有者，
+// 可用区为空字符串的节点视为同一个可用区，因此未标注可用区时结果与 GetN 相同
+func GetNZones(s NodeSelector, key string, n int, zoneOf ZoneFunc) []string {
+	if n <= 1 {
+		// 第一个节点总是所有者，不必计算全部候选节点
+		return s.GetN(key, n)
+	}
+	candidates := s.GetN(key, math.MaxInt32)
+	if len(candidates) <= n {
+		return candidates
+	}
+
+	nodes := make([]string, 0, n)
+	picked := make([]bool, len(candidates))
+	zones := make(map[string]struct{}, n)
+	for i, node := range candidates {
+		zone := zoneOf(node)
+		if _, used := zones[zone]; used {
+			continue
+		}
+		zones[zone] = struct{}{}
+		picked[i] = true
+		nodes = append(nodes, node)
+		if len(nodes) == n {
+			return nodes
+		}
+	}
+	for i, node := range candidates {
+		if !picked[i] {
+			nodes = append(nodes, node)
+			if len(nodes) == n {
+				break
+			}
+		}
+	}
+	return nodes
+}
+
+// PreferZone 将位于 zone 的节点稳定地排到前面，其余节点保持原顺序，用于读请求优先访问本地可用区的副本
+func PreferZone(nodes []string, zone string, zoneOf ZoneFunc) []string {
+	sorted := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if zoneOf(node) == zone {
+			sorted = append(sorted, node)
+		}
+	}
+	for _, node := range nodes {
+		if zoneOf(node) != zone {
+			sorted = append(sorted, node)
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,67 @@
+package consistentHash
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetNZones(t *testing.T) {
+	zones := map[string]string{
+		"a1": "a", "a2": "a", "a3": "a",
+		"b1": "b", "b2": "b",
+		"c1": "c",
+	}
+	zoneOf := func(node string) string { return zones[node] }
+
+	for _, s := range []NodeSelector{New(), NewRendezvous(), NewMaglev(0)} {
+		s.Add("a1", "a2", "a3", "b1", "b2", "c1")
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			nodes := GetNZones(s, key, 3, zoneOf)
+			if len(nodes) != 3 {
+				t.Fatalf("%T key %s: got %v", s, key, nodes)
+			}
+			if nodes[0] != s.GetN(key, 1)[0] {
+				t.Fatalf("%T key %s: first replica %s is not the owner", s, key, nodes[0])
+			}
+			seen := make(map[string]bool)
+			for _, node := range nodes {
+				if seen[zones[node]] {
+					t.Fatalf("%T key %s: replicas %v share a zone", s, key, nodes)
+				}
+				seen[zones[node]] = true
+			}
+
+			// 可用区不足时按原顺序补足且不重复
+			nodes = GetNZones(s, key, 5, zoneOf)
+			distinct := make(map[string]bool)
+			for _, node := range nodes {
+				distinct[node] = true
+			}
+			if len(nodes) != 5 || len(distinct) != 5 {
+				t.Fatalf("%T key %s: got %v, want 5 distinct nodes", s, key, nodes)
+			}
+		}
+	}
+}
+
+func TestGetNZonesUnlabeled(t *testing.T) {
+	m := New()
+	m.Add("a", "b", "c", "d")
+	noZone := func(string) string { return "" }
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		got, want := GetNZones(m, key, 3, noZone), m.GetN(key, 3)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("key %s: got %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestPreferZone(t *testing.T) {
+	zones := map[string]string{"a1": "a", "b1": "b", "a2": "a", "c1": "c"}
+	got := PreferZone([]string{"b1", "a1", "c1", "a2"}, "a", func(node string) string { return zones[node] })
+	if want := "[a1 a2 b1 c1]"; fmt.Sprint(got) != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
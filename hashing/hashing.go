@@ -0,0 +1,84 @@
+// Package hashing 提供缓存内部共享的 64 位哈希函数，用于哈希环的节点放置和存储的分桶。
+// 哈希环要求集群中所有节点对同一个键得到相同的结果，只能使用与进程无关的确定性哈希
+package hashing
+
+import (
+	"hash/maphash"
+	"unsafe"
+)
+
+// Func 64 位哈希函数
+type Func func(data []byte) uint64
+
+// Default 默认哈希函数，用于哈希环和存储分桶
+var Default Func = XXHash
+
+// String 使用 f 计算字符串的哈希，不复制字符串，f 不得修改或保留传入的切片
+func String(f Func, s string) uint64 {
+	return f(unsafe.Slice(unsafe.StringData(s), len(s)))
+}
+
+// FromUint32 把 32 位哈希函数（如 crc32.ChecksumIEEE）包装为 Func，
+// 结果经过混合分散到整个 64 位空间，但不同值的数量仍然只有 2^32 个
+func FromUint32(f func(data []byte) uint32) Func {
+	return func(data []byte) uint64 {
+		return Mix64(uint64(f(data)))
+	}
+}
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// FNV1a 64 位 FNV-1a 哈希，实现简单，短键速度快，但低位分布较差
+func FNV1a(data []byte) uint64 {
+	h := uint64(fnvOffset64)
+	for _, c := range data {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// FNV1aString 计算字符串的 64 位 FNV-1a 哈希
+func FNV1aString(s string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// XXHashString 计算字符串的 XXH64 哈希
+func XXHashString(s string) uint64 {
+	return String(XXHash, s)
+}
+
+// Mix64 splitmix64 的最终混合步骤，使相近的输入得到分布均匀的输出
+func Mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// mapHashSeed MapHash 使用的种子，进程启动时生成后固定不变
+var mapHashSeed = maphash.MakeSeed()
+
+// MapHash 使用运行时的 maphash 计算哈希，速度最快，种子在进程内固定。
+// maphash 的种子只能随机生成，不同进程之间结果不同，只能用于存储分桶等本地用途，不能用于哈希环；
+// 需要跨进程固定种子时使用 NewXXHash
+func MapHash(data []byte) uint64 {
+	return maphash.Bytes(mapHashSeed, data)
+}
+
+// NewMapHash 返回使用指定种子的 maphash，多个组件共用同一个种子时结果一致
+func NewMapHash(seed maphash.Seed) Func {
+	return func(data []byte) uint64 {
+		return maphash.Bytes(seed, data)
+	}
+}
@@ -0,0 +1,139 @@
+package hashing
+
+import (
+	"fmt"
+	"hash/crc32"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"testing"
+)
+
+func TestXXHashVectors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"abc", 0x44bc2cf5ad770999},
+		{"Nobody inspects the spammish repetition", 0xfbcea83c8a378bf1},
+	}
+	for _, tt := range tests {
+		if got := XXHash([]byte(tt.in)); got != tt.want {
+			t.Errorf("XXHash(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+		if got := XXHashString(tt.in); got != tt.want {
+			t.Errorf("XXHashString(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFNV1aVectors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"", 0xcbf29ce484222325},
+		{"a", 0xaf63dc4c8601ec8c},
+		{"foobar", 0x85944171f73967e8},
+	}
+	for _, tt := range tests {
+		if got := FNV1a([]byte(tt.in)); got != tt.want {
+			t.Errorf("FNV1a(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+		if got := FNV1aString(tt.in); got != tt.want {
+			t.Errorf("FNV1aString(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+	}
+}
+
+// chiSquare 把相似的键按高位和低位分桶，计算卡方统计量与自由度之比，均匀分布时接近 1
+func chiSquare(f Func, keys, buckets int, high bool) float64 {
+	counts := make([]int, buckets)
+	shift := 64 - bits.Len(uint(buckets-1))
+	for i := 0; i < keys; i++ {
+		h := String(f, fmt.Sprintf("key-%d", i))
+		if high {
+			counts[h>>shift]++
+		} else {
+			counts[h%uint64(buckets)]++
+		}
+	}
+	expected := float64(keys) / float64(buckets)
+	var chi float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi += d * d / expected
+	}
+	return chi / float64(buckets-1)
+}
+
+func TestDistribution(t *testing.T) {
+	funcs := map[string]Func{
+		"xxhash":  XXHash,
+		"maphash": MapHash,
+		"crc32":   FromUint32(crc32.ChecksumIEEE),
+	}
+	for name, f := range funcs {
+		for _, high := range []bool{true, false} {
+			// 自由度 1023 时 p=0.001 的临界值约为 1.15 倍自由度
+			if r := chiSquare(f, 200000, 1024, high); r > 1.2 {
+				t.Errorf("%s (high bits=%v): chi-square ratio %.3f, want <= 1.2", name, high, r)
+			}
+		}
+	}
+}
+
+func TestAvalanche(t *testing.T) {
+	// 翻转输入的任意一位，输出的每一位应以接近 1/2 的概率翻转
+	const samples = 2000
+	for name, f := range map[string]Func{"xxhash": XXHash, "maphash": MapHash} {
+		var flips [64]int
+		total := 0
+		for i := 0; i < samples; i++ {
+			in := []byte(fmt.Sprintf("node-%08d", i))
+			h := f(in)
+			for bit := 0; bit < len(in)*8; bit++ {
+				in[bit/8] ^= 1 << (bit % 8)
+				diff := h ^ f(in)
+				in[bit/8] ^= 1 << (bit % 8)
+				for j := 0; j < 64; j++ {
+					flips[j] += int(diff >> j & 1)
+				}
+				total++
+			}
+		}
+		for j, c := range flips {
+			if p := float64(c) / float64(total); math.Abs(p-0.5) > 0.02 {
+				t.Errorf("%s: output bit %d flips with probability %.3f", name, j, p)
+			}
+		}
+	}
+}
+
+func TestStringDoesNotAllocate(t *testing.T) {
+	key := "some-cache-key"
+	if n := testing.AllocsPerRun(100, func() { XXHashString(key) }); n != 0 {
+		t.Errorf("XXHashString allocated %.0f times", n)
+	}
+}
+
+func TestSeededHashes(t *testing.T) {
+	// xxHash 官方测试向量：空输入，种子 2654435761
+	if got := NewXXHash(2654435761)(nil); got != 0xac75fda2929b17ef {
+		t.Errorf("NewXXHash(2654435761)(nil) = %#x, want %#x", got, uint64(0xac75fda2929b17ef))
+	}
+	data := []byte("Nobody inspects the spammish repetition")
+	if got, want := NewXXHash(0)(data), XXHash(data); got != want {
+		t.Errorf("NewXXHash(0) = %#x, want %#x", got, want)
+	}
+	if NewXXHash(1)(data) == NewXXHash(2)(data) {
+		t.Error("different seeds should give different hashes")
+	}
+
+	seed := maphash.MakeSeed()
+	if a, b := NewMapHash(seed)(data), NewMapHash(seed)(data); a != b {
+		t.Errorf("NewMapHash with the same seed = %#x and %#x", a, b)
+	}
+}
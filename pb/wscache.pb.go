@@ -26,6 +26,7 @@ type Request struct {
 	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
 	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,9 +82,17 @@ func (x *Request) GetValue() []byte {
 	return nil
 }
 
+func (x *Request) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type ResponseForGet struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,6 +134,13 @@ func (x *ResponseForGet) GetValue() []byte {
 	return nil
 }
 
+func (x *ResponseForGet) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type ResponseForDelete struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         bool                   `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
@@ -169,23 +185,443 @@ func (x *ResponseForDelete) GetValue() bool {
 	return false
 }
 
+type TagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagRequest) Reset() {
+	*x = TagRequest{}
+	mi := &file_pb_wscache_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagRequest) ProtoMessage() {}
+
+func (x *TagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagRequest.ProtoReflect.Descriptor instead.
+func (*TagRequest) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TagRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *TagRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type ResponseForInvalidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int64                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseForInvalidate) Reset() {
+	*x = ResponseForInvalidate{}
+	mi := &file_pb_wscache_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseForInvalidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseForInvalidate) ProtoMessage() {}
+
+func (x *ResponseForInvalidate) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseForInvalidate.ProtoReflect.Descriptor instead.
+func (*ResponseForInvalidate) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResponseForInvalidate) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+type HotKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HotKeysRequest) Reset() {
+	*x = HotKeysRequest{}
+	mi := &file_pb_wscache_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HotKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HotKeysRequest) ProtoMessage() {}
+
+func (x *HotKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HotKeysRequest.ProtoReflect.Descriptor instead.
+func (*HotKeysRequest) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HotKeysRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *HotKeysRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type HotKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Rate          float64                `protobuf:"fixed64,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HotKey) Reset() {
+	*x = HotKey{}
+	mi := &file_pb_wscache_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HotKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HotKey) ProtoMessage() {}
+
+func (x *HotKey) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HotKey.ProtoReflect.Descriptor instead.
+func (*HotKey) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *HotKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *HotKey) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *HotKey) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type HotKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*HotKey              `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HotKeysResponse) Reset() {
+	*x = HotKeysResponse{}
+	mi := &file_pb_wscache_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HotKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HotKeysResponse) ProtoMessage() {}
+
+func (x *HotKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HotKeysResponse.ProtoReflect.Descriptor instead.
+func (*HotKeysResponse) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HotKeysResponse) GetKeys() []*HotKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type MigrateEntry struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Group          string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Key            string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value          []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	ExpireUnixNano int64                  `protobuf:"varint,4,opt,name=expire_unix_nano,json=expireUnixNano,proto3" json:"expire_unix_nano,omitempty"`
+	Tags           []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Seq            uint64                 `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`
+	Flush          bool                   `protobuf:"varint,7,opt,name=flush,proto3" json:"flush,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MigrateEntry) Reset() {
+	*x = MigrateEntry{}
+	mi := &file_pb_wscache_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MigrateEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrateEntry) ProtoMessage() {}
+
+func (x *MigrateEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrateEntry.ProtoReflect.Descriptor instead.
+func (*MigrateEntry) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MigrateEntry) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *MigrateEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MigrateEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *MigrateEntry) GetExpireUnixNano() int64 {
+	if x != nil {
+		return x.ExpireUnixNano
+	}
+	return 0
+}
+
+func (x *MigrateEntry) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *MigrateEntry) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *MigrateEntry) GetFlush() bool {
+	if x != nil {
+		return x.Flush
+	}
+	return false
+}
+
+type MigrateAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MigrateAck) Reset() {
+	*x = MigrateAck{}
+	mi := &file_pb_wscache_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MigrateAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrateAck) ProtoMessage() {}
+
+func (x *MigrateAck) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_wscache_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrateAck.ProtoReflect.Descriptor instead.
+func (*MigrateAck) Descriptor() ([]byte, []int) {
+	return file_pb_wscache_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MigrateAck) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
 var File_pb_wscache_proto protoreflect.FileDescriptor
 
 const file_pb_wscache_proto_rawDesc = "" +
 	"\n" +
-	"\x10pb/wscache.proto\x12\x02pb\"G\n" +
+	"\x10pb/wscache.proto\x12\x02pb\"[\n" +
 	"\aRequest\x12\x14\n" +
 	"\x05group\x18\x01 \x01(\tR\x05group\x12\x10\n" +
 	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x03 \x01(\fR\x05value\"&\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\":\n" +
 	"\x0eResponseForGet\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\fR\x05value\")\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\")\n" +
 	"\x11ResponseForDelete\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\bR\x05value2\x87\x01\n" +
+	"\x05value\x18\x01 \x01(\bR\x05value\"4\n" +
+	"\n" +
+	"TagRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\"1\n" +
+	"\x15ResponseForInvalidate\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x03R\adeleted\"<\n" +
+	"\x0eHotKeysRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"D\n" +
+	"\x06HotKey\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\x01R\x04rate\"1\n" +
+	"\x0fHotKeysResponse\x12\x1e\n" +
+	"\x04keys\x18\x01 \x03(\v2\n" +
+	".pb.HotKeyR\x04keys\"\xb2\x01\n" +
+	"\fMigrateEntry\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\x12(\n" +
+	"\x10expire_unix_nano\x18\x04 \x01(\x03R\x0eexpireUnixNano\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x10\n" +
+	"\x03seq\x18\x06 \x01(\x04R\x03seq\x12\x14\n" +
+	"\x05flush\x18\a \x01(\bR\x05flush\"\x1e\n" +
+	"\n" +
+	"MigrateAck\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq2\xa8\x02\n" +
 	"\awsCache\x12&\n" +
 	"\x03Get\x12\v.pb.Request\x1a\x12.pb.ResponseForGet\x12&\n" +
 	"\x03Set\x12\v.pb.Request\x1a\x12.pb.ResponseForGet\x12,\n" +
-	"\x06Delete\x12\v.pb.Request\x1a\x15.pb.ResponseForDeleteB\x04Z\x02./b\x06proto3"
+	"\x06Delete\x12\v.pb.Request\x1a\x15.pb.ResponseForDelete\x122\n" +
+	"\aHotKeys\x12\x12.pb.HotKeysRequest\x1a\x13.pb.HotKeysResponse\x12:\n" +
+	"\rInvalidateTag\x12\x0e.pb.TagRequest\x1a\x19.pb.ResponseForInvalidate\x12/\n" +
+	"\aMigrate\x12\x10.pb.MigrateEntry\x1a\x0e.pb.MigrateAck(\x010\x01B\x04Z\x02./b\x06proto3"
 
 var (
 	file_pb_wscache_proto_rawDescOnce sync.Once
@@ -199,24 +635,38 @@ func file_pb_wscache_proto_rawDescGZIP() []byte {
 	return file_pb_wscache_proto_rawDescData
 }
 
-var file_pb_wscache_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pb_wscache_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_pb_wscache_proto_goTypes = []any{
-	(*Request)(nil),           // 0: pb.Request
-	(*ResponseForGet)(nil),    // 1: pb.ResponseForGet
-	(*ResponseForDelete)(nil), // 2: pb.ResponseForDelete
+	(*Request)(nil),               // 0: pb.Request
+	(*ResponseForGet)(nil),        // 1: pb.ResponseForGet
+	(*ResponseForDelete)(nil),     // 2: pb.ResponseForDelete
+	(*TagRequest)(nil),            // 3: pb.TagRequest
+	(*ResponseForInvalidate)(nil), // 4: pb.ResponseForInvalidate
+	(*HotKeysRequest)(nil),        // 5: pb.HotKeysRequest
+	(*HotKey)(nil),                // 6: pb.HotKey
+	(*HotKeysResponse)(nil),       // 7: pb.HotKeysResponse
+	(*MigrateEntry)(nil),          // 8: pb.MigrateEntry
+	(*MigrateAck)(nil),            // 9: pb.MigrateAck
 }
 var file_pb_wscache_proto_depIdxs = []int32{
-	0, // 0: pb.wsCache.Get:input_type -> pb.Request
-	0, // 1: pb.wsCache.Set:input_type -> pb.Request
-	0, // 2: pb.wsCache.Delete:input_type -> pb.Request
-	1, // 3: pb.wsCache.Get:output_type -> pb.ResponseForGet
-	1, // 4: pb.wsCache.Set:output_type -> pb.ResponseForGet
-	2, // 5: pb.wsCache.Delete:output_type -> pb.ResponseForDelete
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	6, // 0: pb.HotKeysResponse.keys:type_name -> pb.HotKey
+	0, // 1: pb.wsCache.Get:input_type -> pb.Request
+	0, // 2: pb.wsCache.Set:input_type -> pb.Request
+	0, // 3: pb.wsCache.Delete:input_type -> pb.Request
+	5, // 4: pb.wsCache.HotKeys:input_type -> pb.HotKeysRequest
+	3, // 5: pb.wsCache.InvalidateTag:input_type -> pb.TagRequest
+	8, // 6: pb.wsCache.Migrate:input_type -> pb.MigrateEntry
+	1, // 7: pb.wsCache.Get:output_type -> pb.ResponseForGet
+	1, // 8: pb.wsCache.Set:output_type -> pb.ResponseForGet
+	2, // 9: pb.wsCache.Delete:output_type -> pb.ResponseForDelete
+	7, // 10: pb.wsCache.HotKeys:output_type -> pb.HotKeysResponse
+	4, // 11: pb.wsCache.InvalidateTag:output_type -> pb.ResponseForInvalidate
+	9, // 12: pb.wsCache.Migrate:output_type -> pb.MigrateAck
+	7, // [7:13] is the sub-list for method output_type
+	1, // [1:7] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_pb_wscache_proto_init() }
@@ -230,7 +680,7 @@ func file_pb_wscache_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pb_wscache_proto_rawDesc), len(file_pb_wscache_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	WsCache_Get_FullMethodName    = "/pb.wsCache/Get"
-	WsCache_Set_FullMethodName    = "/pb.wsCache/Set"
-	WsCache_Delete_FullMethodName = "/pb.wsCache/Delete"
+	WsCache_Get_FullMethodName           = "/pb.wsCache/Get"
+	WsCache_Set_FullMethodName           = "/pb.wsCache/Set"
+	WsCache_Delete_FullMethodName        = "/pb.wsCache/Delete"
+	WsCache_HotKeys_FullMethodName       = "/pb.wsCache/HotKeys"
+	WsCache_InvalidateTag_FullMethodName = "/pb.wsCache/InvalidateTag"
+	WsCache_Migrate_FullMethodName       = "/pb.wsCache/Migrate"
 )
 
 // WsCacheClient is the client API for WsCache service.
@@ -31,6 +34,9 @@ type WsCacheClient interface {
 	Get(ctx context.Context, in *Request, opts ...grpc.CallOption) (*ResponseForGet, error)
 	Set(ctx context.Context, in *Request, opts ...grpc.CallOption) (*ResponseForGet, error)
 	Delete(ctx context.Context, in *Request, opts ...grpc.CallOption) (*ResponseForDelete, error)
+	HotKeys(ctx context.Context, in *HotKeysRequest, opts ...grpc.CallOption) (*HotKeysResponse, error)
+	InvalidateTag(ctx context.Context, in *TagRequest, opts ...grpc.CallOption) (*ResponseForInvalidate, error)
+	Migrate(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MigrateEntry, MigrateAck], error)
 }
 
 type wsCacheClient struct {
@@ -71,6 +77,39 @@ func (c *wsCacheClient) Delete(ctx context.Context, in *Request, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *wsCacheClient) HotKeys(ctx context.Context, in *HotKeysRequest, opts ...grpc.CallOption) (*HotKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HotKeysResponse)
+	err := c.cc.Invoke(ctx, WsCache_HotKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wsCacheClient) InvalidateTag(ctx context.Context, in *TagRequest, opts ...grpc.CallOption) (*ResponseForInvalidate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResponseForInvalidate)
+	err := c.cc.Invoke(ctx, WsCache_InvalidateTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wsCacheClient) Migrate(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MigrateEntry, MigrateAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WsCache_ServiceDesc.Streams[0], WsCache_Migrate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[MigrateEntry, MigrateAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WsCache_MigrateClient = grpc.BidiStreamingClient[MigrateEntry, MigrateAck]
+
 // WsCacheServer is the server API for WsCache service.
 // All implementations must embed UnimplementedWsCacheServer
 // for forward compatibility.
@@ -78,6 +117,9 @@ type WsCacheServer interface {
 	Get(context.Context, *Request) (*ResponseForGet, error)
 	Set(context.Context, *Request) (*ResponseForGet, error)
 	Delete(context.Context, *Request) (*ResponseForDelete, error)
+	HotKeys(context.Context, *HotKeysRequest) (*HotKeysResponse, error)
+	InvalidateTag(context.Context, *TagRequest) (*ResponseForInvalidate, error)
+	Migrate(grpc.BidiStreamingServer[MigrateEntry, MigrateAck]) error
 	mustEmbedUnimplementedWsCacheServer()
 }
 
@@ -97,6 +139,15 @@ func (UnimplementedWsCacheServer) Set(context.Context, *Request) (*ResponseForGe
 func (UnimplementedWsCacheServer) Delete(context.Context, *Request) (*ResponseForDelete, error) {
 	return nil, status.Error(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedWsCacheServer) HotKeys(context.Context, *HotKeysRequest) (*HotKeysResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method HotKeys not implemented")
+}
+func (UnimplementedWsCacheServer) InvalidateTag(context.Context, *TagRequest) (*ResponseForInvalidate, error) {
+	return nil, status.Error(codes.Unimplemented, "method InvalidateTag not implemented")
+}
+func (UnimplementedWsCacheServer) Migrate(grpc.BidiStreamingServer[MigrateEntry, MigrateAck]) error {
+	return status.Error(codes.Unimplemented, "method Migrate not implemented")
+}
 func (UnimplementedWsCacheServer) mustEmbedUnimplementedWsCacheServer() {}
 func (UnimplementedWsCacheServer) testEmbeddedByValue()                 {}
 
@@ -172,6 +223,49 @@ func _WsCache_Delete_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WsCache_HotKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HotKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WsCacheServer).HotKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WsCache_HotKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WsCacheServer).HotKeys(ctx, req.(*HotKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WsCache_InvalidateTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WsCacheServer).InvalidateTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WsCache_InvalidateTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WsCacheServer).InvalidateTag(ctx, req.(*TagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WsCache_Migrate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WsCacheServer).Migrate(&grpc.GenericServerStream[MigrateEntry, MigrateAck]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WsCache_MigrateServer = grpc.BidiStreamingServer[MigrateEntry, MigrateAck]
+
 // WsCache_ServiceDesc is the grpc.ServiceDesc for WsCache service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,7 +285,22 @@ var WsCache_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _WsCache_Delete_Handler,
 		},
+		{
+			MethodName: "HotKeys",
+			Handler:    _WsCache_HotKeys_Handler,
+		},
+		{
+			MethodName: "InvalidateTag",
+			Handler:    _WsCache_InvalidateTag_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Migrate",
+			Handler:       _WsCache_Migrate_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pb/wscache.proto",
 }
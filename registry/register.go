@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"time"
@@ -23,8 +24,55 @@ var DefaultConfig = &Config{
 	DialTimeout: 5 * time.Second,
 }
 
+// Instance 注册到etcd的服务实例元数据
+type Instance struct {
+	Addr   string `json:"addr"`           // 服务地址
+	Weight int    `json:"weight"`         // 节点权重，决定在哈希环上的虚拟节点数
+	Zone   string `json:"zone,omitempty"` // 可用区或机架，副本优先分散在不同可用区
+}
+
+// RegisterOption 定义注册选项
+type RegisterOption func(*Instance)
+
+// WithWeight 设置节点权重，默认为1
+func WithWeight(weight int) RegisterOption {
+	return func(ins *Instance) {
+		ins.Weight = weight
+	}
+}
+
+// WithZone 设置节点所在的可用区或机架
+func WithZone(zone string) RegisterOption {
+	return func(ins *Instance) {
+		ins.Zone = zone
+	}
+}
+
+// MetadataPrefix 返回服务实例元数据 key 的前缀。服务 key 的值只保存地址，
+// 与只认地址的旧版本节点兼容；权重、可用区等元数据单独保存在此前缀下，不在旧节点监听的前缀内
+func MetadataPrefix(svcName string) string {
+	return fmt.Sprintf("/services-meta/%s/", svcName)
+}
+
+// MetadataKey 返回实例元数据的 key
+func MetadataKey(svcName, addr string) string {
+	return MetadataPrefix(svcName) + addr
+}
+
+// ParseInstance 解析etcd中保存的实例信息，值可以是地址，也可以是元数据 JSON
+func ParseInstance(value []byte) Instance {
+	var ins Instance
+	if err := json.Unmarshal(value, &ins); err != nil || ins.Addr == "" {
+		ins = Instance{Addr: string(value)}
+	}
+	if ins.Weight <= 0 {
+		ins.Weight = 1
+	}
+	return ins
+}
+
 // Register 注册服务到etcd
-func Register(svcName string, address string, stopCh <-chan error) error {
+func Register(svcName string, address string, stopCh <-chan error, opts ...RegisterOption) error {
 	cli, err := clientv3.New(clientv3.Config{
 		Endpoints:   DefaultConfig.Endpoints,
 		DialTimeout: DefaultConfig.DialTimeout,
@@ -49,9 +97,21 @@ func Register(svcName string, address string, stopCh <-chan error) error {
 		cli.Close()
 		return fmt.Errorf("failed to create etcd lease: %v", err)
 	}
-	// 注册服务，使用完整的key路径
+	ins := Instance{Addr: address, Weight: 1}
+	for _, opt := range opts {
+		opt(&ins)
+	}
+	value, err := json.Marshal(ins)
+	if err != nil {
+		cli.Close()
+		return fmt.Errorf("failed to marshal instance: %v", err)
+	}
+	// 注册服务，使用完整的key路径，值只保存地址；元数据与服务 key 在同一事务中写入并共用租约
 	key := fmt.Sprintf("/services/%s/%s", svcName, address)
-	_, err = cli.Put(context.Background(), key, address, clientv3.WithLease(lease.ID))
+	_, err = cli.Txn(context.Background()).Then(
+		clientv3.OpPut(MetadataKey(svcName, address), string(value), clientv3.WithLease(lease.ID)),
+		clientv3.OpPut(key, address, clientv3.WithLease(lease.ID)),
+	).Commit()
 	if err != nil {
 		cli.Close()
 		return fmt.Errorf("failed to put key-value to etcd: %v", err)
@@ -86,7 +146,9 @@ func Register(svcName string, address string, stopCh <-chan error) error {
 	}()
 	logger.L().Info("Service register success",
 		zap.String("svcName", svcName),
-		zap.String("address", address))
+		zap.String("address", address),
+		zap.Int("weight", ins.Weight),
+		zap.String("zone", ins.Zone))
 	return nil
 }
 func getLocalIP() (string, error) {
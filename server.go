@@ -3,21 +3,26 @@ package wscache
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/wsss777/LRUCache/cache"
+	"github.com/wsss777/LRUCache/cluster"
 	"github.com/wsss777/LRUCache/logger"
 	pb "github.com/wsss777/LRUCache/pb"
 	"github.com/wsss777/LRUCache/registry"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 // Server 定义缓存服务器
@@ -40,6 +45,9 @@ type ServerOptions struct {
 	TLS           bool          // 是否启用TLS
 	CertFile      string        // 证书文件
 	KeyFile       string        // 密钥文件
+	Weight        int           // 节点权重，注册到etcd供其他节点构建哈希环
+	Zone          string        // 节点所在的可用区，注册到etcd供其他节点放置副本
+	NoRegistry    bool          // 不连接etcd也不注册服务，配合 cluster.StaticPicker 使用
 }
 
 // DefaultServerOptions 默认配置
@@ -47,6 +55,7 @@ var DefaultServerOptions = &ServerOptions{
 	EtcdEndpoints: []string{"localhost:2379"},
 	DialTimeout:   5 * time.Second,
 	MaxMsgSize:    4 << 20, //4MB
+	Weight:        1,
 }
 
 // ServerOption 定义选项函数类型
@@ -66,6 +75,28 @@ func WithDialTimeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// WithWeight 设置节点权重，容量越大的机器权重越高，
+// 节点选择器需要通过 cluster.WithWeight 使用相同的权重
+func WithWeight(weight int) ServerOption {
+	return func(o *ServerOptions) {
+		o.Weight = weight
+	}
+}
+
+// WithZone 设置节点所在的可用区或机架，节点选择器需要通过 cluster.WithZone 使用相同的可用区
+func WithZone(zone string) ServerOption {
+	return func(o *ServerOptions) {
+		o.Zone = zone
+	}
+}
+
+// WithoutRegistry 不连接etcd也不注册服务，节点通过 cluster.NewStaticPicker 的固定列表互相发现
+func WithoutRegistry() ServerOption {
+	return func(o *ServerOptions) {
+		o.NoRegistry = true
+	}
+}
+
 // WithTLS 设置TLS配置
 func WithTLS(certFile, keyFile string) ServerOption {
 	return func(o *ServerOptions) {
@@ -77,17 +108,22 @@ func WithTLS(certFile, keyFile string) ServerOption {
 
 // NewServer 创建新的服务器实例
 func NewServer(addr, svcName string, opts ...ServerOption) (*Server, error) {
-	options := DefaultServerOptions
+	// 复制默认配置，避免选项修改包级变量影响之后创建的服务器
+	options := *DefaultServerOptions
 	for _, opt := range opts {
-		opt(options)
+		opt(&options)
 	}
 	// 创建etcd客户端
-	etcdCli, err := clientv3.New(clientv3.Config{
-		Endpoints:   options.EtcdEndpoints,
-		DialTimeout: options.DialTimeout,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to Create etcd client: %v", err)
+	var etcdCli *clientv3.Client
+	if !options.NoRegistry {
+		var err error
+		etcdCli, err = clientv3.New(clientv3.Config{
+			Endpoints:   options.EtcdEndpoints,
+			DialTimeout: options.DialTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to Create etcd client: %v", err)
+		}
 	}
 	// 创建gRPC服务器
 	var serverOpts []grpc.ServerOption
@@ -106,7 +142,7 @@ func NewServer(addr, svcName string, opts ...ServerOption) (*Server, error) {
 		grpcServer: grpc.NewServer(serverOpts...),
 		etcdCli:    etcdCli,
 		stopCh:     make(chan error),
-		opts:       options,
+		opts:       &options,
 	}
 	// 注册服务
 	pb.RegisterWsCacheServer(srv.grpcServer, srv)
@@ -127,7 +163,11 @@ func (s *Server) Start() error {
 	// 注册到etcd
 	stopCh := make(chan error)
 	go func() {
-		if err := registry.Register(s.svcName, s.addr, stopCh); err != nil {
+		if s.opts.NoRegistry {
+			return
+		}
+		if err := registry.Register(s.svcName, s.addr, stopCh,
+			registry.WithWeight(s.opts.Weight), registry.WithZone(s.opts.Zone)); err != nil {
 			logger.L().Error("failed to register service",
 				zap.String("addr", s.addr),
 				zap.Error(err))
@@ -155,12 +195,28 @@ func (s *Server) Get(ctx context.Context, req *pb.Request) (*pb.ResponseForGet,
 		return nil, fmt.Errorf("group %s not found", req.Group)
 
 	}
+	if cluster.IsPeerRequest(ctx) {
+		ctx = context.WithValue(ctx, "from_peer", true)
+	}
 	view, err := group.Get(ctx, req.Key)
 	if err != nil {
+		// 不存在的键返回 NotFound，便于对端识别并写入负缓存
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
 		return nil, err
 	}
+	// 其他节点（包括接替加载请求）接收编码格式，由对端按同样的压缩设置存储；
+	// 普通客户端接收解码后的原始数据
+	value := view.WireBytes()
+	if !cluster.IsPeerRequest(ctx) && !cluster.IsFallbackRequest(ctx) {
+		if value, err = view.Decode(); err != nil {
+			return nil, status.Error(codes.DataLoss, err.Error())
+		}
+	}
 	return &pb.ResponseForGet{
-		Value: view.ByteSlice(),
+		Value: value,
+		Tags:  view.Tags(),
 	}, nil
 }
 
@@ -171,13 +227,12 @@ func (s *Server) Set(ctx context.Context, req *pb.Request) (*pb.ResponseForGet,
 		return nil, fmt.Errorf("group %s not found", req.Group)
 	}
 
-	// // 从 context 中获取标记，如果没有则创建新的 context
-	// fromPeer := ctx.Value("from_peer")
-	// if fromPeer == nil {
-	// 	ctx = context.WithValue(ctx, "from_peer", true)
-	// }
+	// 其他节点同步过来的请求，标记后不再继续同步
+	if cluster.IsPeerRequest(ctx) {
+		ctx = context.WithValue(ctx, "from_peer", true)
+	}
 
-	if err := group.Set(ctx, req.Key, req.Value); err != nil {
+	if err := group.Set(ctx, req.Key, req.Value, req.Tags...); err != nil {
 		return nil, err
 	}
 
@@ -191,10 +246,93 @@ func (s *Server) Delete(ctx context.Context, req *pb.Request) (*pb.ResponseForDe
 		return nil, fmt.Errorf("group %s not found", req.Group)
 	}
 
+	if cluster.IsPeerRequest(ctx) {
+		ctx = context.WithValue(ctx, "from_peer", true)
+	}
 	err := group.Delete(ctx, req.Key)
 	return &pb.ResponseForDelete{Value: err == nil}, err
 }
 
+// InvalidateTag 实现Cache服务的InvalidateTag方法
+func (s *Server) InvalidateTag(ctx context.Context, req *pb.TagRequest) (*pb.ResponseForInvalidate, error) {
+	group := cache.GetGroup(req.Group)
+	if group == nil {
+		return nil, fmt.Errorf("group %s not found", req.Group)
+	}
+
+	if cluster.IsPeerRequest(ctx) {
+		ctx = context.WithValue(ctx, "from_peer", true)
+	}
+	deleted, err := group.InvalidateTag(ctx, req.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ResponseForInvalidate{Deleted: int64(deleted)}, nil
+}
+
+// Migrate 实现Cache服务的Migrate方法，接收其他节点迁移过来的条目，
+// 收到带 flush 标记的条目后确认此前的所有条目
+func (s *Server) Migrate(stream pb.WsCache_MigrateServer) error {
+	var received, imported int
+	defer func() {
+		logger.L().Info("migration received",
+			zap.Int("entries", received),
+			zap.Int("imported", imported))
+	}()
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		received++
+
+		// 本节点没有该组时丢弃条目，对端仍会删除本地副本
+		if group := cache.GetGroup(entry.Group); group != nil {
+			var expire time.Time
+			if entry.ExpireUnixNano > 0 {
+				expire = time.Unix(0, entry.ExpireUnixNano)
+			}
+			ok, err := group.ImportEntry(entry.Key, entry.Value, expire, entry.Tags)
+			if err != nil {
+				logger.L().Warn("failed to import migrated entry",
+					zap.String("group", entry.Group),
+					zap.String("key", entry.Key),
+					zap.Error(err))
+			} else if ok {
+				imported++
+			}
+		}
+
+		if entry.Flush {
+			if err := stream.Send(&pb.MigrateAck{Seq: entry.Seq}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// HotKeys 实现Cache服务的HotKeys方法，返回组内访问最多的键
+func (s *Server) HotKeys(ctx context.Context, req *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	group := cache.GetGroup(req.Group)
+	if group == nil {
+		return nil, fmt.Errorf("group %s not found", req.Group)
+	}
+
+	hotKeys := group.HotKeys(int(req.Limit))
+	resp := &pb.HotKeysResponse{Keys: make([]*pb.HotKey, 0, len(hotKeys))}
+	for _, k := range hotKeys {
+		resp.Keys = append(resp.Keys, &pb.HotKey{
+			Key:   k.Key,
+			Count: k.Count,
+			Rate:  k.Rate,
+		})
+	}
+	return resp, nil
+}
+
 // loadTLSCredentials 加载TLS证书
 func loadTLSCredentials(certFile, keyFile string) (credentials.TransportCredentials, error) {
 	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
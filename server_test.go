@@ -0,0 +1,260 @@
+package wscache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wsss777/LRUCache/cache"
+	"github.com/wsss777/LRUCache/circuitBreaker"
+	"github.com/wsss777/LRUCache/cluster"
+	pb "github.com/wsss777/LRUCache/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// freeAddr 返回一个本机空闲地址
+func freeAddr(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+// 测试不依赖 etcd 的两个节点：静态节点列表路由到远端节点，新节点加入时通知订阅者
+func TestStaticPickerWithoutRegistry(t *testing.T) {
+	remoteAddr, localAddr := freeAddr(t), freeAddr(t)
+
+	srv, err := NewServer(remoteAddr, "static-test", WithoutRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	defer srv.Stop()
+
+	g := cache.NewGroup("static-test", 1<<20, cache.GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte("remote-" + key), nil
+	}))
+	defer g.Close()
+
+	picker, err := cluster.NewStaticPicker(localAddr, []string{localAddr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer picker.Close()
+
+	joined := make(chan string, 1)
+	picker.OnPeerJoin(func(addr string, peer cluster.Peer) {
+		joined <- addr
+	})
+	picker.Set(localAddr, remoteAddr)
+	select {
+	case addr := <-joined:
+		if addr != remoteAddr {
+			t.Fatalf("joined %s, want %s", addr, remoteAddr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no join notification")
+	}
+
+	// 找一个归属远端节点的键，经 gRPC 从远端组加载
+	var routed bool
+	for i := 0; i < 100 && !routed; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		peer, ok, isSelf := picker.PickPeer(key)
+		if !ok || isSelf {
+			continue
+		}
+		value, err := peer.Get("static-test", key)
+		if err != nil {
+			t.Fatalf("get %s from remote peer: %v", key, err)
+		}
+		if got := string(value); got != "remote-"+key {
+			t.Fatalf("got %q, want %q", got, "remote-"+key)
+		}
+		routed = true
+	}
+	if !routed {
+		t.Fatal("no key routed to the remote peer")
+	}
+
+	// 移除远端节点后所有键都归属自身
+	picker.Set()
+	if _, ok, isSelf := picker.PickPeer("any"); !ok || !isSelf {
+		t.Fatal("all keys should be owned by self after removing peers")
+	}
+}
+
+// 测试服务器选项不会修改默认配置
+func TestServerOptionsDoNotLeak(t *testing.T) {
+	before := *DefaultServerOptions
+	srv, err := NewServer(freeAddr(t), "options-test", WithoutRegistry(), WithWeight(5), WithZone("z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	if d := DefaultServerOptions; d.NoRegistry != before.NoRegistry || d.Weight != before.Weight || d.Zone != before.Zone {
+		t.Fatalf("DefaultServerOptions changed: %+v", *DefaultServerOptions)
+	}
+	if !srv.opts.NoRegistry || srv.opts.Weight != 5 || srv.opts.Zone != "z" {
+		t.Fatalf("options not applied: %+v", *srv.opts)
+	}
+}
+
+// 测试启用压缩时普通客户端收到解码后的值，其他节点收到编码格式
+func TestGetDecodesForClients(t *testing.T) {
+	addr := freeAddr(t)
+	srv, err := NewServer(addr, "decode-test", WithoutRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	defer srv.Stop()
+
+	g := cache.NewGroup("decode-test", 1<<20, cache.GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, cache.ErrNotFound
+	}), cache.WithCompression(cache.CompressionGzip, 0))
+	defer g.Close()
+	value := strings.Repeat("compressible ", 100)
+	if err := g.Set(context.Background(), "key", []byte(value)); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cli := pb.NewWsCacheClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	resp, err := cli.Get(ctx, &pb.Request{Group: "decode-test", Key: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(resp.GetValue()); got != value {
+		t.Fatalf("client got %d bytes, want the decoded %d bytes", len(got), len(value))
+	}
+
+	peerCtx := metadata.AppendToOutgoingContext(ctx, "wscache-from-peer", "1")
+	resp, err = cli.Get(peerCtx, &pb.Request{Group: "decode-test", Key: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wire := resp.GetValue(); len(wire) == 0 || cache.Compression(wire[0]) != cache.CompressionGzip || len(wire) >= len(value) {
+		t.Fatalf("peer got %d bytes, want the gzip-encoded value", len(wire))
+	}
+}
+
+// failingServer 所有 Get 都返回 Unavailable 的节点
+type failingServer struct {
+	pb.UnimplementedWsCacheServer
+	calls int32
+}
+
+func (s *failingServer) Get(ctx context.Context, req *pb.Request) (*pb.ResponseForGet, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return nil, status.Error(codes.Unavailable, "overloaded")
+}
+
+// 测试节点熔断打开后，归属该节点的键直接从本地数据源加载
+func TestPeerBreakerFallsBackToLocal(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := &failingServer{}
+	gs := grpc.NewServer()
+	pb.RegisterWsCacheServer(gs, remote)
+	go gs.Serve(lis)
+	defer gs.Stop()
+	remoteAddr, localAddr := lis.Addr().String(), freeAddr(t)
+
+	g := cache.NewGroup("peer-breaker-test", 1<<20, cache.GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte("local-" + key), nil
+	}))
+	defer g.Close()
+	picker, err := cluster.NewStaticPicker(localAddr, []string{remoteAddr}, cluster.WithPeerBreaker(&circuitBreaker.Config{
+		FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer picker.Close()
+	g.RegisterPeers(picker)
+
+	var keys []string
+	for i := 0; len(keys) < 2; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if _, ok, isSelf := picker.PickPeer(key); ok && !isSelf {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		view, err := g.Get(context.Background(), key)
+		if err != nil || view.String() != "local-"+key {
+			t.Fatalf("get %s: %q, %v, want the local value", key, view.String(), err)
+		}
+	}
+	if state := picker.Stats()["breaker_"+remoteAddr]; state != "open" {
+		t.Fatalf("breaker state %v, want open", state)
+	}
+	if n := atomic.LoadInt32(&remote.calls); n != 1 {
+		t.Fatalf("remote called %d times, want 1 before the breaker opened", n)
+	}
+}
+
+// 测试 InvalidateTag RPC 删除组内带有该标签的键
+func TestServerInvalidateTag(t *testing.T) {
+	addr := freeAddr(t)
+	srv, err := NewServer(addr, "tag-test", WithoutRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	defer srv.Stop()
+
+	g := cache.NewGroup("tag-test", 1<<20, cache.GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, cache.ErrNotFound
+	}))
+	defer g.Close()
+	ctx := context.Background()
+	g.Set(ctx, "a", []byte("1"), "user")
+	g.Set(ctx, "b", []byte("2"), "user", "vip")
+	g.Set(ctx, "c", []byte("3"), "vip")
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	rpcCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	resp, err := pb.NewWsCacheClient(conn).InvalidateTag(rpcCtx, &pb.TagRequest{Group: "tag-test", Tag: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetDeleted() != 2 {
+		t.Fatalf("deleted %d keys, want 2", resp.GetDeleted())
+	}
+	for key, want := range map[string]bool{"a": false, "b": false, "c": true} {
+		if _, err := g.Get(ctx, key); (err == nil) != want {
+			t.Errorf("get %s after invalidation: %v", key, err)
+		}
+	}
+}
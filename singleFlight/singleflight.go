@@ -1,33 +1,172 @@
 package singleFlight
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout 共享加载的默认超时时间
+const DefaultTimeout = 30 * time.Second
+
+// ErrGoexit fn 调用了 runtime.Goexit 时返回给等待者的错误
+var ErrGoexit = errors.New("singleflight: runtime.Goexit was called")
+
+// PanicError fn 发生 panic 时返回给所有等待者的错误
+type PanicError struct {
+	Value interface{} // recover() 得到的值
+	Stack []byte      // panic 时的调用栈
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("singleflight: panic in fn: %v\n\n%s", p.Value, p.Stack)
+}
 
 // 代表正在进行或已结束的请求
 type call struct {
-	wg  sync.WaitGroup
-	val interface{}
-	err error
+	done chan struct{} // 加载完成后关闭
+	val  interface{}
+	err  error
+	dups int64 // 共享本次结果的重复调用数
+}
+
+// Result DoChan 返回的结果
+type Result struct {
+	Val    interface{}
+	Err    error
+	Shared bool // 结果是否被多个调用者共享
 }
 
 type Group struct {
-	m sync.Map // 使用sync.Map来优化并发性能
+	m        sync.Map      // 使用sync.Map来优化并发性能
+	Timeout  time.Duration // DoContext 中共享加载的超时时间，0 表示使用 DefaultTimeout
+	calls    int64         // 实际执行 fn 的次数
+	dups     int64         // 被合并的重复调用次数
+	inFlight int64         // 正在执行的 fn 数量
 }
 
-// Do 针对相同的key，保证多次调用Do()，都只会调用一次fn
+// Do 针对相同的key，保证多次调用Do()，都只会调用一次fn。
+// fn 发生 panic 时所有调用者（包括执行者自己）都会收到 *PanicError
 func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
-	if existing, ok := g.m.Load(key); ok {
-		c := existing.(*call)
-		c.wg.Wait()
-		return c.val, c.err
+	c, leader := g.join(key)
+	if leader {
+		g.exec(key, c, fn)
+	} else {
+		<-c.done
+	}
+	return c.val, c.err
+}
+
+// DoContext 与 Do 相同，但每个调用者可以通过自己的 ctx 独立放弃等待。
+// 共享的加载在脱离取消信号的上下文中继续执行，并受 Timeout 限制，
+// 因此某个调用者取消不会影响其他等待者拿到的结果
+func (g *Group) DoContext(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	c, leader := g.join(key)
+	if leader {
+		// 保留 ctx 中的值，但不继承其取消信号
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), g.timeout())
+		go func() {
+			defer cancel()
+			g.exec(key, c, func() (interface{}, error) {
+				return fn(loadCtx)
+			})
+		}()
 	}
 
-	c := &call{}
-	c.wg.Add(1)
-	g.m.Store(key, c)
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
 
-	c.val, c.err = fn()
-	c.wg.Done()
+// DoChan 与 Do 相同，但立即返回一个在结果就绪时接收 Result 的通道
+func (g *Group) DoChan(key string, fn func() (interface{}, error)) <-chan Result {
+	ch := make(chan Result, 1)
+	c, leader := g.join(key)
+	if leader {
+		// fn 可能调用 runtime.Goexit，因此不能与发送结果放在同一个 goroutine
+		go g.exec(key, c, fn)
+	}
+	go func() {
+		<-c.done
+		ch <- Result{Val: c.val, Err: c.err, Shared: atomic.LoadInt64(&c.dups) > 0}
+	}()
+	return ch
+}
 
+// Forget 让 key 对应的进行中请求不再被后续调用共享，之后的调用会重新执行 fn
+func (g *Group) Forget(key string) {
 	g.m.Delete(key)
-	return c.val, c.err
+}
+
+// Stats 返回合并统计信息
+func (g *Group) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"calls":        atomic.LoadInt64(&g.calls),
+		"deduplicated": atomic.LoadInt64(&g.dups),
+		"in_flight":    atomic.LoadInt64(&g.inFlight),
+	}
+}
+
+// join 获取 key 对应的进行中请求，不存在时创建并返回 leader=true。
+// 使用 LoadOrStore 原子地完成注册，保证同一时刻只有一个 leader
+func (g *Group) join(key string) (*call, bool) {
+	c := &call{done: make(chan struct{})}
+	if existing, loaded := g.m.LoadOrStore(key, c); loaded {
+		c = existing.(*call)
+		atomic.AddInt64(&c.dups, 1)
+		atomic.AddInt64(&g.dups, 1)
+		return c, false
+	}
+	atomic.AddInt64(&g.calls, 1)
+	atomic.AddInt64(&g.inFlight, 1)
+	return c, true
+}
+
+// exec 执行 fn 并通知所有等待者。fn 发生 panic 或调用 runtime.Goexit 时，
+// 等待者会收到 PanicError 或 ErrGoexit，而不会永远阻塞
+func (g *Group) exec(key string, c *call, fn func() (interface{}, error)) {
+	normalReturn := false
+	recovered := false
+
+	defer func() {
+		// 既没有正常返回也没有 recover 到 panic，说明调用了 runtime.Goexit
+		if !normalReturn && !recovered {
+			c.err = ErrGoexit
+		}
+		atomic.AddInt64(&g.inFlight, -1)
+		// 只删除自己，避免 Forget 之后误删新的请求
+		g.m.CompareAndDelete(key, c)
+		close(c.done)
+	}()
+
+	func() {
+		defer func() {
+			if !normalReturn {
+				if r := recover(); r != nil {
+					c.val, c.err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+				}
+			}
+		}()
+		c.val, c.err = fn()
+		normalReturn = true
+	}()
+
+	if !normalReturn {
+		recovered = true
+	}
+}
+
+// timeout 返回共享加载的超时时间
+func (g *Group) timeout() time.Duration {
+	if g.Timeout > 0 {
+		return g.Timeout
+	}
+	return DefaultTimeout
 }
@@ -0,0 +1,138 @@
+package singleFlight
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试并发调用只执行一次 fn
+func TestDo(t *testing.T) {
+	var g Group
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.Do("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return "value", nil
+			})
+			if err != nil || v.(string) != "value" {
+				t.Errorf("结果不一致: %v, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("fn 应只执行一次，实际执行%d次", calls)
+	}
+}
+
+// 测试某个调用者取消不影响其他等待者
+func TestDoContextCancel(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "value", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := g.DoContext(ctx, "key", fn)
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	resCh := g.DoChan("key", func() (interface{}, error) {
+		t.Error("不应重复执行")
+		return nil, nil
+	})
+
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消的调用者应返回 context.Canceled，实际为%v", err)
+	}
+
+	close(release)
+	res := <-resCh
+	if res.Err != nil || res.Val.(string) != "value" {
+		t.Fatalf("其他等待者应拿到正常结果，实际为%v, %v", res.Val, res.Err)
+	}
+}
+
+// 测试 Forget 之后重新执行 fn
+func TestForget(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	first := g.DoChan("key", func() (interface{}, error) {
+		<-release
+		return 1, nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	g.Forget("key")
+
+	v, _ := g.Do("key", func() (interface{}, error) {
+		return 2, nil
+	})
+	if v.(int) != 2 {
+		t.Fatalf("Forget 后应重新执行，实际结果为%v", v)
+	}
+	close(release)
+	if res := <-first; res.Val.(int) != 1 {
+		t.Fatalf("原请求结果应为1，实际为%v", res.Val)
+	}
+}
+
+// 测试 fn panic 时所有等待者都收到错误而不会阻塞
+func TestDoPanic(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	leader := g.DoChan("key", func() (interface{}, error) {
+		<-release
+		panic("boom")
+	})
+	time.Sleep(10 * time.Millisecond)
+	waiter := g.DoChan("key", func() (interface{}, error) {
+		return nil, nil
+	})
+	close(release)
+
+	for _, ch := range []<-chan Result{leader, waiter} {
+		select {
+		case res := <-ch:
+			var pe *PanicError
+			if !errors.As(res.Err, &pe) || pe.Value != "boom" {
+				t.Fatalf("应收到 PanicError，实际为%v", res.Err)
+			}
+			if !res.Shared {
+				t.Fatal("结果应标记为共享")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("等待者被阻塞")
+		}
+	}
+	if dups := g.Stats()["deduplicated"].(int64); dups != 1 {
+		t.Fatalf("合并次数应为1，实际为%d", dups)
+	}
+}
+
+// 测试 fn 调用 runtime.Goexit 时等待者收到 ErrGoexit
+func TestDoGoexit(t *testing.T) {
+	var g Group
+	res := <-g.DoChan("key", func() (interface{}, error) {
+		runtime.Goexit()
+		return nil, nil
+	})
+	if !errors.Is(res.Err, ErrGoexit) {
+		t.Fatalf("应收到 ErrGoexit，实际为%v", res.Err)
+	}
+}
@@ -147,6 +147,21 @@ func (c *lruCache) Len() int {
 	return c.list.Len()
 }
 
+// Keys 返回所有未过期的键
+func (c *lruCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now()
+	keys := make([]string, 0, len(c.items))
+	for key := range c.items {
+		if expTime, hasExp := c.expires[key]; hasExp && now.After(expTime) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // removeElement 从缓存中删除元素
 func (c *lruCache) removeElement(elem *list.Element) {
 	entry := elem.Value.(*lruEntry)
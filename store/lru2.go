@@ -5,6 +5,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/wsss777/LRUCache/hashing"
 )
 
 const (
@@ -18,6 +20,7 @@ type lru2Store struct {
 	onEvicted   func(k string, v Value)
 	cleanupTick *time.Ticker
 	mask        int32
+	hash        hashing.Func // 键分桶使用的哈希函数
 }
 
 type node struct {
@@ -53,6 +56,10 @@ func newLRU2Cache(opts Options) *lru2Store {
 		onEvicted:   opts.OnEvicted,
 		cleanupTick: time.NewTicker(opts.CleanupInterval),
 		mask:        int32(mask),
+		hash:        opts.HashFunc,
+	}
+	if s.hash == nil {
+		s.hash = hashing.Default
 	}
 	for i := range s.caches {
 		s.caches[i][0] = Create(opts.CapPerBucket)
@@ -65,7 +72,7 @@ func newLRU2Cache(opts Options) *lru2Store {
 }
 
 func (s *lru2Store) Get(key string) (Value, bool) {
-	idx := hashBKRD(key) & s.mask
+	idx := s.bucket(key)
 	s.locks[idx].Lock()
 	defer s.locks[idx].Unlock()
 	currentTime := Now()
@@ -107,7 +114,7 @@ func (s *lru2Store) SetWithExpiration(key string, value Value, expiration time.D
 	if expiration > 0 {
 		expireAt = Now() + int64(expiration.Nanoseconds())
 	}
-	idx := hashBKRD(key) & s.mask
+	idx := s.bucket(key)
 	s.locks[idx].Lock()
 	defer s.locks[idx].Unlock()
 
@@ -116,7 +123,7 @@ func (s *lru2Store) SetWithExpiration(key string, value Value, expiration time.D
 }
 
 func (s *lru2Store) Delete(key string) bool {
-	idx := hashBKRD(key) & s.mask
+	idx := s.bucket(key)
 	s.locks[idx].Lock()
 	defer s.locks[idx].Unlock()
 
@@ -167,6 +174,30 @@ func (s *lru2Store) Len() int {
 	return count
 }
 
+// Keys 返回所有未过期的键，同时存在于两级缓存中的键只返回一次
+func (s *lru2Store) Keys() []string {
+	now := Now()
+	seen := make(map[string]struct{})
+	var keys []string
+	walker := func(key string, value Value, expireAt int64) bool {
+		if expireAt <= now {
+			return true
+		}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+		return true
+	}
+	for i := range s.caches {
+		s.locks[i].Lock()
+		s.caches[i][0].walk(walker)
+		s.caches[i][1].walk(walker)
+		s.locks[i].Unlock()
+	}
+	return keys
+}
+
 func (s *lru2Store) Close() {
 	if s.cleanupTick != nil {
 		s.cleanupTick.Stop()
@@ -192,12 +223,9 @@ func init() {
 	}()
 }
 
-// 实现了 BKDR 哈希算法，用于计算键的哈希值
-func hashBKRD(key string) (hash int32) {
-	for i := 0; i < len(key); i++ {
-		hash = hash*131 + int32(key[i])
-	}
-	return hash
+// bucket 返回键所在的桶
+func (s *lru2Store) bucket(key string) int32 {
+	return int32(hashing.String(s.hash, key)) & s.mask
 }
 
 // maskOfNextPowOf2 计算大于或等于输入值的最近 2 的幂次方减一作为掩码值
@@ -335,6 +363,10 @@ func (c *cache) adjust(idx, f, t uint16) {
 			c.dlnk[c.dlnk[0][Head]][p] = idx
 		}
 		c.dlnk[0][Head] = idx
+		// 链表原本为空时同时是尾部
+		if c.dlnk[0][Tail] == 0 {
+			c.dlnk[0][Tail] = idx
+		}
 	} else {
 		// 插入到尾部
 		c.dlnk[idx][n] = 0
@@ -343,6 +375,10 @@ func (c *cache) adjust(idx, f, t uint16) {
 			c.dlnk[c.dlnk[0][Tail]][n] = idx
 		}
 		c.dlnk[0][Tail] = idx
+		// 链表原本为空时同时是头部
+		if c.dlnk[0][Head] == 0 {
+			c.dlnk[0][Head] = idx
+		}
 	}
 
 }
@@ -345,6 +345,39 @@ func TestCacheAdjust(t *testing.T) {
 	}
 }
 
+// 测试链表只有一个节点时 adjust 同时维护头部和尾部
+func TestCacheAdjustSingleNode(t *testing.T) {
+	c := Create(1)
+	c.put("key1", testValue("value1"), Now()+int64(time.Hour), nil)
+	idx := c.hmap["key1"]
+
+	// 删除时节点被摘下再插入到尾部，链表原本为空，插入后既是头部也是尾部
+	c.del("key1")
+	if c.dlnk[0][Head] != idx || c.dlnk[0][Tail] != idx {
+		t.Fatalf("after del: head=%d tail=%d, want both %d", c.dlnk[0][Head], c.dlnk[0][Tail], idx)
+	}
+
+	// 重新写入后可以遍历到
+	c.put("key1", testValue("value2"), Now()+int64(time.Hour), nil)
+	if c.dlnk[0][Head] != idx || c.dlnk[0][Tail] != idx {
+		t.Fatalf("after put: head=%d tail=%d, want both %d", c.dlnk[0][Head], c.dlnk[0][Tail], idx)
+	}
+	var keys []string
+	c.walk(func(key string, value Value, expireAt int64) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 1 || keys[0] != "key1" {
+		t.Fatalf("walk returned %v, want [key1]", keys)
+	}
+
+	// 容量已满时替换尾部节点
+	c.put("key2", testValue("value3"), Now()+int64(time.Hour), nil)
+	if _, ok := c.hmap["key2"]; !ok || len(c.hmap) != 1 {
+		t.Fatalf("key2 should replace key1, hmap=%v", c.hmap)
+	}
+}
+
 // 测试lru2Store的基本接口
 func TestLRU2StoreBasicOperations(t *testing.T) {
 	var evictedKeys []string
@@ -550,9 +583,20 @@ func TestLRU2StoreClear(t *testing.T) {
 	store := newLRU2Cache(opts)
 	defer store.Close()
 
+	// 每个桶放满 5 个键，避免键在桶间分布不均时触发淘汰
+	var keys []string
+	perBucket := make(map[int32]int)
+	for i := 0; len(keys) < 10; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if b := store.bucket(key); perBucket[b] < 5 {
+			perBucket[b]++
+			keys = append(keys, key)
+		}
+	}
+
 	// 添加一些项
-	for i := 0; i < 10; i++ {
-		store.Set(fmt.Sprintf("key%d", i), testValue(fmt.Sprintf("value%d", i)))
+	for _, key := range keys {
+		store.Set(key, testValue("value-"+key))
 	}
 
 	// 验证长度
@@ -569,10 +613,10 @@ func TestLRU2StoreClear(t *testing.T) {
 	}
 
 	// 验证项已被删除
-	for i := 0; i < 10; i++ {
-		_, found := store.Get(fmt.Sprintf("key%d", i))
+	for _, key := range keys {
+		_, found := store.Get(key)
 		if found {
-			t.Errorf("key%d should not be found after Clear", i)
+			t.Errorf("%s should not be found after Clear", key)
 		}
 	}
 }
@@ -591,7 +635,7 @@ func TestLRU2Store_Get(t *testing.T) {
 	defer store.Close()
 
 	// 向一级缓存添加一个项
-	idx := hashBKRD("test-key") & store.mask
+	idx := store.bucket("test-key")
 	store.caches[idx][0].put("test-key", testValue("test-value"), Now()+int64(time.Hour), nil)
 
 	// 使用_get直接从一级缓存获取
@@ -642,7 +686,7 @@ func TestLRU2StoreDelete(t *testing.T) {
 	defer store.Close()
 
 	// 向一级缓存添加一个项
-	idx := hashBKRD("test-key") & store.mask
+	idx := store.bucket("test-key")
 	store.caches[idx][0].put("test-key", testValue("test-value"), Now()+int64(time.Hour), nil)
 
 	// 向二级缓存添加一个项
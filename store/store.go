@@ -1,6 +1,10 @@
 package store
 
-import "time"
+import (
+	"time"
+
+	"github.com/wsss777/LRUCache/hashing"
+)
 
 type Value interface {
 	Len() int
@@ -16,6 +20,11 @@ type Store interface {
 	Close()
 }
 
+// KeyLister 可选接口，列出存储中所有未过期的键
+type KeyLister interface {
+	Keys() []string
+}
+
 type CacheType string
 
 const (
@@ -30,6 +39,7 @@ type Options struct {
 	Level2Cap       uint16 // lru-2 中二级缓存的容量（lru-2）
 	CleanupInterval time.Duration
 	OnEvicted       func(key string, value Value)
+	HashFunc        hashing.Func // 键分桶使用的哈希函数（lru-2），nil 表示 hashing.Default
 }
 
 func NewOptions() Options {